@@ -0,0 +1,76 @@
+package weightedoption
+
+import "math/bits"
+
+// aliasTable holds the tables for selecting Options with Vose's alias method.
+// Every Option has a column of height totalWeight; a draw picks a column and a
+// height in it, returning the column's Option below prob and its alias above.
+type aliasTable struct {
+	prob  []uint
+	alias []int
+}
+
+// buildAliasTable builds the Selector's aliasTable from its running total
+// weights, keeping every probability exact.
+func (s *Selector[DataType, WeightType]) buildAliasTable() error {
+	n := uint(len(s.options))
+	if hi, _ := bits.Mul(n, s.totalWeight); hi != 0 {
+		return ErrTotalWeightOverflow
+	}
+
+	table := &aliasTable{
+		prob:  make([]uint, n),
+		alias: make([]int, n),
+	}
+
+	// Scale every weight by n, so the average column holds exactly totalWeight
+	scaled := make([]uint, n)
+	var small, large []int
+	var prev uint
+	for i, sum := range s.cumulativeWeightSums {
+		scaled[i] = (sum - prev) * n
+		prev = sum
+		if scaled[i] < s.totalWeight {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		l := small[len(small)-1]
+		small = small[:len(small)-1]
+		g := large[len(large)-1]
+		large = large[:len(large)-1]
+
+		table.prob[l] = scaled[l]
+		table.alias[l] = g
+
+		// Move the part of g's weight which fills up l's column
+		scaled[g] -= s.totalWeight - scaled[l]
+		if scaled[g] < s.totalWeight {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+
+	// Whatever is left fills its column exactly
+	for _, i := range append(small, large...) {
+		table.prob[i] = s.totalWeight
+		table.alias[i] = i
+	}
+
+	s.alias = table
+	return nil
+}
+
+// selectAlias returns the index of an Option selected with the aliasTable.
+func (s Selector[DataType, WeightType]) selectAlias() int {
+	x := s.uintN(uint(len(s.options)) * s.totalWeight)
+	i, height := x/s.totalWeight, x%s.totalWeight
+	if height < s.alias.prob[i] {
+		return int(i)
+	}
+	return s.alias.alias[i]
+}
@@ -0,0 +1,81 @@
+package weightedoption
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBuildAliasTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{name: "single option", weights: []int{7}},
+		{name: "equal weights", weights: []int{2, 2, 2, 2}},
+		{name: "increasing weights", weights: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{name: "skewed weights", weights: []int{1000, 1, 1, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			options := make([]Option[int, int], len(tt.weights))
+			for i, w := range tt.weights {
+				options[i] = NewOption(i, w)
+			}
+
+			s, err := NewSelectorWith(options, WithAlgorithm(AlgorithmAlias))
+			if err != nil {
+				t.Fatal("Failed to create Selector:", err)
+			}
+
+			// Every Option's share of the columns must equal its weight scaled by n
+			n := uint(len(tt.weights))
+			mass := make([]uint, n)
+			for i := range s.alias.prob {
+				mass[i] += s.alias.prob[i]
+				mass[s.alias.alias[i]] += s.totalWeight - s.alias.prob[i]
+			}
+			for i, w := range tt.weights {
+				if want := uint(w) * n; mass[i] != want {
+					t.Errorf("option %d alias mass = %d, want %d", i, mass[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelector_SelectAlias(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	picker, err := NewSelectorWith(options, WithAlgorithm(AlgorithmAlias))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	counts := make(map[int]int)
+	for i := 0; i < testIterations; i++ {
+		counts[picker.Select()]++
+	}
+
+	verifyFrequencyCounts(t, counts, options)
+}
+
+func BenchmarkSelectAlias(b *testing.B) {
+	for n := BMMinOptions; n <= BMMaxOptions; n *= 10 {
+		b.Run(fmt.Sprintf("size=%s", fmt1eN(n)), func(b *testing.B) {
+			options := mockOptions(n)
+			selector, err := NewSelectorWith(options, WithAlgorithm(AlgorithmAlias))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_ = selector.Select()
+			}
+		})
+	}
+}
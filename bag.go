@@ -0,0 +1,115 @@
+package weightedoption
+
+import (
+	"math"
+	"sync"
+)
+
+// BagOption configures a BagSelector created by NewBagSelector.
+type BagOption func(*bagConfig)
+
+type bagConfig struct {
+	multiplier      uint
+	refillAtOrBelow uint
+}
+
+// WithBagMultiplier puts each Option into the bag its weight multiplied by m
+// times, lengthening the cycle over which the realised frequencies match the
+// weights. m less than 1 is treated as 1.
+func WithBagMultiplier(m uint) BagOption {
+	return func(c *bagConfig) {
+		c.multiplier = max(m, 1)
+	}
+}
+
+// WithPartialRefill adds a full bag to the remaining Options as soon as n or
+// fewer are left, rather than waiting for the bag to empty, so the last
+// Options of a cycle are less predictable. n is capped at one less than the
+// size of a full bag.
+func WithPartialRefill(n uint) BagOption {
+	return func(c *bagConfig) {
+		c.refillAtOrBelow = n
+	}
+}
+
+// BagSelector selects Options without replacement from a bag holding each
+// Option as many times as its integer weight, refilling the bag when it runs
+// out. Over every full bag the realised frequencies of the Options match their
+// weights exactly; with float weights the scaled integer weights are used. It
+// is safe for concurrent use.
+type BagSelector[DataType any, WeightType WeightConstraint] struct {
+	mu              sync.Mutex
+	selector        *Selector[DataType, WeightType]
+	full            []uint
+	remaining       *fenwick
+	refillAtOrBelow uint
+}
+
+// NewBagSelector creates a new BagSelector with a full bag of the Options of s,
+// configured by the provided BagOptions. If the size of two full bags exceeds
+// the max uint value for this system's architecture ErrTotalWeightOverflow is
+// returned.
+func NewBagSelector[DataType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	cfg ...BagOption,
+) (*BagSelector[DataType, WeightType], error) {
+	c := bagConfig{multiplier: 1}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+
+	// A partial refill can leave up to a full bag on top of a full bag
+	if s.totalWeight > math.MaxUint/2/c.multiplier {
+		return nil, ErrTotalWeightOverflow
+	}
+
+	full := make([]uint, s.Len())
+	for i := range full {
+		full[i] = s.weight(i) * c.multiplier
+	}
+	remaining := newFenwick(full)
+
+	return &BagSelector[DataType, WeightType]{
+		selector:        s,
+		full:            full,
+		remaining:       remaining,
+		refillAtOrBelow: min(c.refillAtOrBelow, remaining.total-1),
+	}, nil
+}
+
+// Select returns a single DataType drawn from the bag, refilling the bag first
+// if it is due a refill.
+func (b *BagSelector[DataType, WeightType]) Select() DataType {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.remaining.total <= b.refillAtOrBelow {
+		b.refill()
+	}
+
+	i := b.remaining.find(b.selector.uintN(b.remaining.total))
+	b.remaining.sub(i, 1)
+	return b.selector.options[i]
+}
+
+// Remaining returns the number of Options left in the bag.
+func (b *BagSelector[DataType, WeightType]) Remaining() uint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining.total
+}
+
+// Reset empties the bag and fills it with a single full bag.
+func (b *BagSelector[DataType, WeightType]) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remaining = newFenwick(b.full)
+}
+
+// refill adds a full bag to the remaining Options. It must be called while
+// holding b.mu.
+func (b *BagSelector[DataType, WeightType]) refill() {
+	for i, w := range b.full {
+		b.remaining.add(i, w)
+	}
+}
@@ -0,0 +1,122 @@
+package weightedoption
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewBagSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		cs            []Option[rune, int]
+		cfg           []BagOption
+		wantRemaining uint
+		wantErr       error
+	}{
+		{
+			name:          "bag holds the total weight",
+			cs:            []Option[rune, int]{{Data: 'a', Weight: 5}, {Data: 'b', Weight: 95}},
+			wantRemaining: 100,
+		},
+		{
+			name:          "multiplier",
+			cs:            []Option[rune, int]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 3}},
+			cfg:           []BagOption{WithBagMultiplier(3)},
+			wantRemaining: 12,
+		},
+		{
+			name:    "overflow",
+			cs:      []Option[rune, int]{{Data: 'a', Weight: math.MaxInt}},
+			cfg:     []BagOption{WithBagMultiplier(2)},
+			wantErr: ErrTotalWeightOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b, err := NewBagSelector(mustSelector(t, tt.cs...), tt.cfg...)
+			if err != tt.wantErr {
+				t.Fatalf("NewBagSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && b.Remaining() != tt.wantRemaining {
+				t.Errorf("Remaining() = %d, want %d", b.Remaining(), tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestBagSelector_Select(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption("rare", 5), NewOption("common", 95))
+	b, err := NewBagSelector(s)
+	if err != nil {
+		t.Fatal("Failed to create BagSelector:", err)
+	}
+
+	for cycle := 0; cycle < 100; cycle++ {
+		counts := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			counts[b.Select()]++
+		}
+		if counts["rare"] != 5 || counts["common"] != 95 {
+			t.Fatalf("cycle %d counts = %v, want 5 rare and 95 common", cycle, counts)
+		}
+	}
+}
+
+func TestBagSelector_Multiplier(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 1), NewOption('b', 3))
+	b, err := NewBagSelector(s, WithBagMultiplier(2))
+	if err != nil {
+		t.Fatal("Failed to create BagSelector:", err)
+	}
+
+	counts := make(map[rune]int)
+	for i := 0; i < 8; i++ {
+		counts[b.Select()]++
+	}
+	if counts['a'] != 2 || counts['b'] != 6 {
+		t.Errorf("counts = %v, want 2 a and 6 b", counts)
+	}
+	if got := b.Remaining(); got != 0 {
+		t.Errorf("Remaining() = %d, want 0", got)
+	}
+}
+
+func TestBagSelector_PartialRefill(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 1), NewOption('b', 1), NewOption('c', 1))
+	b, err := NewBagSelector(s, WithPartialRefill(1))
+	if err != nil {
+		t.Fatal("Failed to create BagSelector:", err)
+	}
+
+	counts := make(map[rune]int)
+	for i := 0; i < 3000; i++ {
+		counts[b.Select()]++
+		if got := b.Remaining(); got < 1 {
+			t.Fatalf("Remaining() = %d after selection %d, want at least 1", got, i)
+		}
+	}
+	// 3000 selections plus the Options still in the bag make up whole bags
+	remaining := int(b.Remaining())
+	if (3000+remaining)%3 != 0 {
+		t.Errorf("%d selected and %d remaining don't make whole bags", 3000, remaining)
+	}
+	for data, count := range counts {
+		if count < 999-remaining || count > 1001 {
+			t.Errorf("%c selected %d times, want about 1000", data, count)
+		}
+	}
+
+	b.Reset()
+	if got := b.Remaining(); got != 3 {
+		t.Errorf("Remaining() after Reset() = %d, want 3", got)
+	}
+}
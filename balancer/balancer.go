@@ -0,0 +1,404 @@
+// Package balancer provides an http.RoundTripper which spreads requests over
+// weighted upstream backends with a weightedoption.Selector, ejecting backends
+// which fail and probing them back in.
+package balancer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+const (
+	defaultMaxAttempts      = 2
+	defaultFailureThreshold = 3
+	defaultEjectionDuration = 30 * time.Second
+	// maxDrainBytes is how much of a discarded body is read so its connection
+	// can be reused; larger bodies are closed unread, as net/http does.
+	maxDrainBytes = 4 << 10
+)
+
+var (
+	// ErrNoHealthyBackend is returned by Transport.RoundTrip when every backend
+	// which hasn't already been tried for the request is ejected.
+	ErrNoHealthyBackend = errors.New("no healthy backend")
+	// ErrInvalidBackend is returned by NewTransport for a backend without an
+	// absolute URL.
+	ErrInvalidBackend = errors.New("invalid backend: URL must have a scheme and host")
+)
+
+// TransportOption configures a Transport created by NewTransport.
+type TransportOption func(*transportConfig)
+
+type transportConfig struct {
+	next             http.RoundTripper
+	maxAttempts      int
+	failureThreshold int
+	ejectionDuration time.Duration
+	probePath        string
+	probeInterval    time.Duration
+	now              func() time.Time
+}
+
+// WithRoundTripper makes the Transport send requests to backends with next
+// instead of http.DefaultTransport.
+func WithRoundTripper(next http.RoundTripper) TransportOption {
+	return func(c *transportConfig) {
+		c.next = next
+	}
+}
+
+// WithMaxAttempts sets how many backends an idempotent request is tried on
+// before giving up. Requests which aren't idempotent are only ever tried once.
+// The default is 2.
+func WithMaxAttempts(n int) TransportOption {
+	return func(c *transportConfig) {
+		c.maxAttempts = max(n, 1)
+	}
+}
+
+// WithEjection makes a backend be ejected for d after threshold consecutive
+// failed requests. A request fails when sending it returns an error or its
+// response has a 5xx status. The default is 3 failures and 30 seconds.
+func WithEjection(threshold int, d time.Duration) TransportOption {
+	return func(c *transportConfig) {
+		c.failureThreshold = max(threshold, 1)
+		c.ejectionDuration = d
+	}
+}
+
+// WithHealthCheck makes the Transport send a GET request for path to every
+// backend each interval. A backend whose probe fails is ejected, and an
+// ejected backend whose probe succeeds is let back in straight away. A probe
+// succeeds when its response has a 2xx or 3xx status within interval.
+func WithHealthCheck(path string, interval time.Duration) TransportOption {
+	return func(c *transportConfig) {
+		c.probePath = path
+		c.probeInterval = interval
+	}
+}
+
+// WithClock makes the Transport call now instead of time.Now to find the
+// current time.
+func WithClock(now func() time.Time) TransportOption {
+	return func(c *transportConfig) {
+		c.now = now
+	}
+}
+
+// backendState tracks the health of a single backend.
+type backendState struct {
+	failures     int
+	ejectedUntil time.Time
+}
+
+// Transport is an http.RoundTripper which sends each request to a backend
+// selected by weight from the backends which aren't ejected. Idempotent
+// requests which fail are retried on a backend they haven't been tried on.
+// It is safe for concurrent use, and must be closed when health checks are
+// enabled to stop probing.
+type Transport struct {
+	backends        []*url.URL
+	selectExcluding func(exclude func(i int, data int) bool) (int, error)
+	cfg             transportConfig
+
+	mu     sync.Mutex
+	states []backendState
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTransport creates a new Transport for the provided backends, configured
+// by the provided TransportOptions. Each Option's Data is the base URL of a
+// backend which a request's path is appended to. The same rules as
+// weightedoption.NewSelector apply to the weights.
+func NewTransport[WeightType weightedoption.WeightConstraint](
+	backends []weightedoption.Option[*url.URL, WeightType],
+	cfg ...TransportOption,
+) (*Transport, error) {
+	c := transportConfig{
+		next:             http.DefaultTransport,
+		maxAttempts:      defaultMaxAttempts,
+		failureThreshold: defaultFailureThreshold,
+		ejectionDuration: defaultEjectionDuration,
+		now:              time.Now,
+	}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+
+	urls := make([]*url.URL, len(backends))
+	opts := make([]weightedoption.Option[int, WeightType], len(backends))
+	for i, b := range backends {
+		if b.Data == nil || b.Data.Scheme == "" || b.Data.Host == "" {
+			return nil, ErrInvalidBackend
+		}
+		urls[i] = b.Data
+		opts[i] = weightedoption.NewOption(i, b.Weight)
+	}
+
+	selector, err := weightedoption.NewSelector(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transport{
+		backends: urls,
+		// Only SelectExcluding is needed, which keeps Transport free of the
+		// weight type
+		selectExcluding: selector.SelectExcluding,
+		cfg:             c,
+		states:          make([]backendState, len(urls)),
+	}
+	if c.probeInterval > 0 {
+		t.stop = make(chan struct{})
+		t.done = make(chan struct{})
+		go t.probeLoop()
+	}
+	return t, nil
+}
+
+// RoundTrip sends req to a backend selected by weight from the backends which
+// aren't ejected. If req is idempotent and fails it is sent again to another
+// backend, up to the configured maximum attempts. When every attempt fails the
+// last response or error is returned. If every backend is ejected
+// ErrNoHealthyBackend is returned.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if replayable(req) {
+		attempts = t.cfg.maxAttempts
+	}
+	tried := make([]bool, len(t.backends))
+
+	var resp *http.Response
+	var err error
+	for attempt := range attempts {
+		i, pickErr := t.pick(tried)
+		if pickErr != nil {
+			if attempt == 0 {
+				closeBody(req)
+				return nil, pickErr
+			}
+			break
+		}
+		tried[i] = true
+
+		if resp != nil {
+			drain(resp)
+		}
+		out, rewriteErr := t.rewrite(req, i, attempt)
+		if rewriteErr != nil {
+			return nil, rewriteErr
+		}
+
+		resp, err = t.cfg.next.RoundTrip(out)
+		// A request cancelled by its caller says nothing about the backend
+		if req.Context().Err() != nil {
+			break
+		}
+		ok := err == nil && resp.StatusCode < http.StatusInternalServerError
+		t.report(i, ok)
+		if ok {
+			return resp, nil
+		}
+	}
+	return resp, err
+}
+
+// Ejected returns the indexes of the backends which are currently ejected, in
+// ascending order.
+func (t *Transport) Ejected() []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.cfg.now()
+	var ejected []int
+	for i, state := range t.states {
+		if now.Before(state.ejectedUntil) {
+			ejected = append(ejected, i)
+		}
+	}
+	return ejected
+}
+
+// Close stops the health checks, waiting for any in progress to finish. It is
+// safe to call more than once and always returns nil.
+func (t *Transport) Close() error {
+	t.closeOnce.Do(func() {
+		if t.stop != nil {
+			close(t.stop)
+			<-t.done
+		}
+	})
+	return nil
+}
+
+// pick selects a backend which hasn't been tried and isn't ejected.
+func (t *Transport) pick(tried []bool) (int, error) {
+	t.mu.Lock()
+	now := t.cfg.now()
+	excluded := make([]bool, len(t.states))
+	for i, state := range t.states {
+		excluded[i] = tried[i] || now.Before(state.ejectedUntil)
+	}
+	t.mu.Unlock()
+
+	i, err := t.selectExcluding(func(_ int, i int) bool {
+		return excluded[i]
+	})
+	if err != nil {
+		return 0, ErrNoHealthyBackend
+	}
+	return i, nil
+}
+
+// report records the outcome of a request to backend i, ejecting it once it
+// has failed enough times in a row.
+func (t *Transport) report(i int, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := &t.states[i]
+	if ok {
+		state.failures = 0
+		return
+	}
+
+	state.failures++
+	if state.failures >= t.cfg.failureThreshold {
+		state.failures = 0
+		state.ejectedUntil = t.cfg.now().Add(t.cfg.ejectionDuration)
+	}
+}
+
+// rewrite returns a copy of req addressed to backend i, with a fresh body for
+// each attempt after the first.
+func (t *Transport) rewrite(req *http.Request, i, attempt int) (*http.Request, error) {
+	out := req.Clone(req.Context())
+	if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = body
+	}
+
+	backend := t.backends[i]
+	out.URL.Scheme = backend.Scheme
+	out.URL.Host = backend.Host
+	out.URL.Path = joinPath(backend.Path, req.URL.Path)
+	out.URL.RawPath = ""
+	out.Host = ""
+	return out, nil
+}
+
+// probeLoop runs the health checks until the Transport is closed.
+func (t *Transport) probeLoop() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.cfg.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.probeAll()
+		}
+	}
+}
+
+// probeAll probes every backend concurrently and waits for them to finish.
+func (t *Transport) probeAll() {
+	var wg sync.WaitGroup
+	for i := range t.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok := t.probe(i)
+
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			state := &t.states[i]
+			if ok {
+				*state = backendState{}
+				return
+			}
+			state.ejectedUntil = t.cfg.now().Add(t.cfg.ejectionDuration)
+		}()
+	}
+	wg.Wait()
+}
+
+// probe reports whether backend i passes its health check.
+func (t *Transport) probe(i int) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), t.cfg.probeInterval)
+	defer cancel()
+
+	u := *t.backends[i]
+	u.Path = joinPath(u.Path, t.cfg.probePath)
+	u.RawPath = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return false
+	}
+
+	resp, err := t.cfg.next.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	drain(resp)
+	return resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusBadRequest
+}
+
+// replayable reports whether req can safely be sent more than once, using the
+// same rules as http.Transport.
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, key := req.Header["Idempotency-Key"]
+	_, xKey := req.Header["X-Idempotency-Key"]
+	return key || xKey
+}
+
+// joinPath appends path to a backend's base path, making sure the result is
+// absolute as url.URL.JoinPath can leave it relative.
+func joinPath(base, path string) string {
+	base = strings.TrimSuffix(base, "/")
+	if base != "" && !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return base + path
+}
+
+// drain reads the start of resp's body, so a short body's connection can be
+// reused, and closes it.
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	_ = resp.Body.Close()
+}
+
+// closeBody closes req's body, as a RoundTripper must even when it fails.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,403 @@
+package balancer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// testBackend is an httptest.Server which counts its requests and fails while
+// unhealthy.
+type testBackend struct {
+	*httptest.Server
+	name      string
+	requests  atomic.Int64
+	probes    atomic.Int64
+	unhealthy atomic.Bool
+}
+
+func newTestBackend(t *testing.T, name string) *testBackend {
+	t.Helper()
+	b := &testBackend{name: name}
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			b.probes.Add(1)
+		} else {
+			b.requests.Add(1)
+		}
+		if b.unhealthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, b.name+" "+r.URL.Path+" "+string(body))
+	}))
+	t.Cleanup(b.Close)
+	return b
+}
+
+func (b *testBackend) url(t *testing.T) *url.URL {
+	t.Helper()
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		t.Fatal("Failed to parse URL:", err)
+	}
+	return u
+}
+
+// testClock is a clock which only moves when advanced.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestTransport(t *testing.T, backends []weightedoption.Option[*url.URL, int], cfg ...TransportOption) *Transport {
+	t.Helper()
+	tr, err := NewTransport(backends, cfg...)
+	if err != nil {
+		t.Fatal("Failed to create Transport:", err)
+	}
+	t.Cleanup(func() { _ = tr.Close() })
+	return tr
+}
+
+func get(t *testing.T, client *http.Client, method, target, body string) (int, string) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, r)
+	if err != nil {
+		t.Fatal("Failed to create request:", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal("Request failed:", err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	valid, _ := url.Parse("http://example.com")
+	relative, _ := url.Parse("/relative")
+
+	tests := []struct {
+		name     string
+		backends []weightedoption.Option[*url.URL, int]
+		wantErr  error
+	}{
+		{name: "valid", backends: []weightedoption.Option[*url.URL, int]{{Data: valid, Weight: 1}}},
+		{name: "no backends", backends: nil, wantErr: weightedoption.ErrNoValidOptions},
+		{name: "zero weight", backends: []weightedoption.Option[*url.URL, int]{{Data: valid, Weight: 0}}, wantErr: weightedoption.ErrNoValidOptions},
+		{name: "nil URL", backends: []weightedoption.Option[*url.URL, int]{{Data: nil, Weight: 1}}, wantErr: ErrInvalidBackend},
+		{name: "relative URL", backends: []weightedoption.Option[*url.URL, int]{{Data: relative, Weight: 1}}, wantErr: ErrInvalidBackend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tr, err := NewTransport(tt.backends)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewTransport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tr != nil {
+				_ = tr.Close()
+			}
+		})
+	}
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	heavy, light := newTestBackend(t, "heavy"), newTestBackend(t, "light")
+	base := heavy.url(t).JoinPath("/api")
+	tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+		weightedoption.NewOption(base, 9),
+		weightedoption.NewOption(light.url(t), 1),
+	})
+	client := &http.Client{Transport: tr}
+
+	for range 1000 {
+		code, body := get(t, client, http.MethodGet, "http://upstream/items", "")
+		if code != http.StatusOK {
+			t.Fatalf("GET status = %d, want %d: %s", code, http.StatusOK, body)
+		}
+		if body != "heavy /api/items " && body != "light /items " {
+			t.Fatalf("GET body = %q, want the path joined to the backend's", body)
+		}
+	}
+
+	if h, l := heavy.requests.Load(), light.requests.Load(); h < 800 || l < 50 {
+		t.Errorf("requests = %d heavy and %d light, want about 900 and 100", h, l)
+	}
+}
+
+func TestTransport_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantCode int
+	}{
+		{name: "GET is retried", method: http.MethodGet, wantCode: http.StatusOK},
+		{name: "PUT with body is retried", method: http.MethodPut, body: "payload", wantCode: http.StatusOK},
+		{name: "POST is not retried", method: http.MethodPost, body: "payload", wantCode: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			bad, good := newTestBackend(t, "bad"), newTestBackend(t, "good")
+			bad.unhealthy.Store(true)
+			// bad is all but certain to be tried first
+			tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+				weightedoption.NewOption(bad.url(t), 1_000_000),
+				weightedoption.NewOption(good.url(t), 1),
+			}, WithEjection(100, time.Minute))
+			client := &http.Client{Transport: tr}
+
+			for range 10 {
+				code, body := get(t, client, tt.method, "http://upstream/x", tt.body)
+				if code != tt.wantCode {
+					t.Fatalf("%s status = %d, want %d", tt.method, code, tt.wantCode)
+				}
+				if code == http.StatusOK && body != "good /x "+tt.body {
+					t.Fatalf("%s body = %q, want the request replayed to good", tt.method, body)
+				}
+			}
+		})
+	}
+}
+
+// roundTripperFunc is an http.RoundTripper made from a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// endlessBody is a response body which never ends and counts what is read.
+type endlessBody struct {
+	read   atomic.Int64
+	closed atomic.Bool
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	b.read.Add(int64(len(p)))
+	return len(p), nil
+}
+
+func (b *endlessBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func TestTransport_RetryEndlessBody(t *testing.T) {
+	t.Parallel()
+
+	body := &endlessBody{}
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "bad" {
+			return &http.Response{StatusCode: http.StatusBadGateway, Body: body, Request: req}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	})
+	bad, _ := url.Parse("http://bad")
+	good, _ := url.Parse("http://good")
+	tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+		weightedoption.NewOption(bad, 1_000_000),
+		weightedoption.NewOption(good, 1),
+	}, WithRoundTripper(rt))
+
+	req, err := http.NewRequest(http.MethodGet, "http://upstream/x", nil)
+	if err != nil {
+		t.Fatal("Failed to create request:", err)
+	}
+	done := make(chan *http.Response)
+	go func() {
+		resp, _ := tr.RoundTrip(req)
+		done <- resp
+	}()
+	select {
+	case resp := <-done:
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("response = %v, want %d from the retry", resp, http.StatusOK)
+		}
+		_ = resp.Body.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry stalled draining an endless error body")
+	}
+	if read := body.read.Load(); read > maxDrainBytes {
+		t.Errorf("read %d bytes of the error body, want at most about %d", read, maxDrainBytes)
+	}
+	if !body.closed.Load() {
+		t.Error("error body was not closed")
+	}
+}
+
+func TestTransport_PassiveEjection(t *testing.T) {
+	t.Parallel()
+
+	bad, good := newTestBackend(t, "bad"), newTestBackend(t, "good")
+	bad.unhealthy.Store(true)
+	clock := &testClock{now: time.Unix(0, 0)}
+	tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+		weightedoption.NewOption(bad.url(t), 1_000_000),
+		weightedoption.NewOption(good.url(t), 1),
+	}, WithEjection(3, time.Minute), WithClock(clock.Now), WithMaxAttempts(1))
+	client := &http.Client{Transport: tr}
+
+	for range 3 {
+		if code, _ := get(t, client, http.MethodGet, "http://upstream/", ""); code != http.StatusServiceUnavailable {
+			t.Fatalf("GET status = %d before ejection, want %d", code, http.StatusServiceUnavailable)
+		}
+	}
+	if got := tr.Ejected(); len(got) != 1 || got[0] != 0 {
+		t.Fatalf("Ejected() = %v, want [0]", got)
+	}
+
+	for range 10 {
+		if code, _ := get(t, client, http.MethodGet, "http://upstream/", ""); code != http.StatusOK {
+			t.Fatalf("GET status = %d while bad is ejected, want %d", code, http.StatusOK)
+		}
+	}
+	if got := bad.requests.Load(); got != 3 {
+		t.Errorf("bad received %d requests, want 3", got)
+	}
+
+	bad.unhealthy.Store(false)
+	clock.Advance(time.Minute)
+	if got := tr.Ejected(); len(got) != 0 {
+		t.Errorf("Ejected() = %v after the ejection expired, want none", got)
+	}
+	if _, body := get(t, client, http.MethodGet, "http://upstream/", ""); body != "bad / " {
+		t.Errorf("GET body = %q after the ejection expired, want bad", body)
+	}
+}
+
+func TestTransport_NoHealthyBackend(t *testing.T) {
+	t.Parallel()
+
+	bad := newTestBackend(t, "bad")
+	bad.unhealthy.Store(true)
+	tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+		weightedoption.NewOption(bad.url(t), 1),
+	}, WithEjection(1, time.Minute))
+
+	req := httptest.NewRequest(http.MethodGet, "http://upstream/", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal("RoundTrip() error:", err)
+	}
+	drain(resp)
+
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrNoHealthyBackend) {
+		t.Errorf("RoundTrip() error = %v, wantErr %v", err, ErrNoHealthyBackend)
+	}
+}
+
+func TestTransport_HealthCheck(t *testing.T) {
+	t.Parallel()
+
+	flaky, good := newTestBackend(t, "flaky"), newTestBackend(t, "good")
+	flaky.unhealthy.Store(true)
+	tr := newTestTransport(t, []weightedoption.Option[*url.URL, int]{
+		weightedoption.NewOption(flaky.url(t), 1),
+		weightedoption.NewOption(good.url(t), 1),
+	}, WithHealthCheck("/healthz", 10*time.Millisecond), WithEjection(3, time.Hour))
+
+	waitFor(t, "flaky to be ejected", func() bool {
+		got := tr.Ejected()
+		return len(got) == 1 && got[0] == 0
+	})
+
+	flaky.unhealthy.Store(false)
+	waitFor(t, "flaky to be probed back in", func() bool {
+		return len(tr.Ejected()) == 0
+	})
+
+	if err := tr.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	probes := flaky.probes.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := flaky.probes.Load(); got != probes {
+		t.Errorf("flaky was probed %d more times after Close", got-probes)
+	}
+	if err := tr.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		method string
+		body   io.Reader
+		header string
+		want   bool
+	}{
+		{name: "GET", method: http.MethodGet, want: true},
+		{name: "DELETE", method: http.MethodDelete, want: true},
+		{name: "POST", method: http.MethodPost, want: false},
+		{name: "POST with idempotency key", method: http.MethodPost, header: "Idempotency-Key", want: true},
+		{name: "PUT with rewindable body", method: http.MethodPut, body: strings.NewReader("x"), want: true},
+		{name: "PUT with one-shot body", method: http.MethodPut, body: io.MultiReader(strings.NewReader("x")), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(tt.method, "http://upstream/", tt.body)
+			if err != nil {
+				t.Fatal("Failed to create request:", err)
+			}
+			if tt.header != "" {
+				req.Header.Set(tt.header, "key")
+			}
+			if got := replayable(req); got != tt.want {
+				t.Errorf("replayable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package weightedoption
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrInvalidBatchSize is returned by NewGuaranteedBatch when the batch size is less than 1.
+var ErrInvalidBatchSize = errors.New("batch size must be at least 1")
+
+// Batch is a batch of DataType drawn by a GuaranteedBatch.
+type Batch[DataType any] struct {
+	Items []DataType
+	// Forced is the index in Items of the slot replaced to meet the guarantee,
+	// or -1 if the guarantee was met without replacing one.
+	Forced int
+}
+
+// GuaranteedBatch draws fixed size batches from a Selector which contain at
+// least one Option meeting a guarantee, such as a ten-pull containing at least
+// one 4★ or higher drop. When none of a batch's draws meet the guarantee, the
+// last slot is replaced by a draw from a replacement Selector.
+type GuaranteedBatch[DataType comparable, WeightType WeightConstraint] struct {
+	selector    *Selector[DataType, WeightType]
+	size        int
+	meets       func(DataType) bool
+	replacement *Selector[DataType, WeightType]
+}
+
+// NewGuaranteedBatch creates a new GuaranteedBatch which draws batches of size
+// Options from s, where meets reports whether an Option meets the guarantee.
+// Every Option of replacement should meet the guarantee. If replacement is nil
+// the Options of s which meet the guarantee are used, with their probabilities
+// renormalised, and ErrNoValidOptions is returned if there are none.
+func NewGuaranteedBatch[DataType comparable, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	size int,
+	meets func(DataType) bool,
+	replacement *Selector[DataType, WeightType],
+) (*GuaranteedBatch[DataType, WeightType], error) {
+	if size < 1 {
+		return nil, ErrInvalidBatchSize
+	}
+
+	if replacement == nil {
+		var err error
+		replacement, err = Filter(s, meets)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &GuaranteedBatch[DataType, WeightType]{
+		selector:    s,
+		size:        size,
+		meets:       meets,
+		replacement: replacement,
+	}, nil
+}
+
+// Draw returns a Batch which meets the guarantee.
+func (g *GuaranteedBatch[DataType, WeightType]) Draw() Batch[DataType] {
+	batch := Batch[DataType]{Items: g.selector.SelectN(g.size, nil), Forced: -1}
+	for _, data := range batch.Items {
+		if g.meets(data) {
+			return batch
+		}
+	}
+
+	batch.Forced = g.size - 1
+	batch.Items[batch.Forced] = g.replacement.Select()
+	return batch
+}
+
+// SlotRates returns the exact probability of each DataType in every slot of a
+// Batch. Every slot but the last has the probabilities of the Selector. With a
+// chance q of a draw not meeting the guarantee, the last slot loses q^(size-1)
+// of the probability of the Options which don't meet it, and gains q^size of
+// the probability of the replacement's Options.
+func (g *GuaranteedBatch[DataType, WeightType]) SlotRates() []map[DataType]*big.Rat {
+	base := make(map[DataType]*big.Rat)
+	var unmet uint
+	for i, data := range g.selector.options {
+		addRate(base, data, ratio(g.selector.weight(i), g.selector.totalWeight))
+		if !g.meets(data) {
+			unmet += g.selector.weight(i)
+		}
+	}
+
+	q := ratio(unmet, g.selector.totalWeight)
+	qBefore := new(big.Rat).SetInt64(1)
+	for range g.size - 1 {
+		qBefore.Mul(qBefore, q)
+	}
+	qAll := new(big.Rat).Mul(qBefore, q)
+
+	last := make(map[DataType]*big.Rat, len(base))
+	for data, p := range base {
+		p = new(big.Rat).Set(p)
+		if !g.meets(data) {
+			p.Sub(p, new(big.Rat).Mul(p, qBefore))
+		}
+		last[data] = p
+	}
+	for i, data := range g.replacement.options {
+		p := ratio(g.replacement.weight(i), g.replacement.totalWeight)
+		addRate(last, data, p.Mul(p, qAll))
+	}
+
+	slots := make([]map[DataType]*big.Rat, g.size)
+	for i := range g.size - 1 {
+		slots[i] = make(map[DataType]*big.Rat, len(base))
+		for data, p := range base {
+			slots[i][data] = new(big.Rat).Set(p)
+		}
+	}
+	slots[g.size-1] = last
+	return slots
+}
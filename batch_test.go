@@ -0,0 +1,114 @@
+package weightedoption
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestNewGuaranteedBatch(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption(3, 90), NewOption(4, 10))
+	tests := []struct {
+		name    string
+		size    int
+		meets   func(int) bool
+		wantErr error
+	}{
+		{name: "nominal case", size: 10, meets: func(d int) bool { return d >= 4 }},
+		{name: "zero size", size: 0, meets: func(d int) bool { return d >= 4 }, wantErr: ErrInvalidBatchSize},
+		{name: "nothing meets the guarantee", size: 10, meets: func(d int) bool { return d >= 5 }, wantErr: ErrNoValidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewGuaranteedBatch(s, tt.size, tt.meets, nil)
+			if err != tt.wantErr {
+				t.Errorf("NewGuaranteedBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGuaranteedBatch_Draw(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption(3, 90), NewOption(4, 9), NewOption(5, 1))
+	replacement := mustSelector(t, NewOption(4, 1))
+	g, err := NewGuaranteedBatch(s, 10, func(d int) bool { return d >= 4 }, replacement)
+	if err != nil {
+		t.Fatal("Failed to create GuaranteedBatch:", err)
+	}
+
+	forced := 0
+	lastCounts := make(map[int]int)
+	const batches = 200_000
+	for i := 0; i < batches; i++ {
+		batch := g.Draw()
+		if len(batch.Items) != 10 {
+			t.Fatalf("Draw() returned %d items, want 10", len(batch.Items))
+		}
+
+		met := false
+		for _, data := range batch.Items {
+			met = met || data >= 4
+		}
+		if !met {
+			t.Fatalf("Draw() = %v, which doesn't meet the guarantee", batch.Items)
+		}
+
+		switch batch.Forced {
+		case -1:
+			// The guarantee was met without replacing a slot
+		case 9:
+			forced++
+			if batch.Items[9] != 4 {
+				t.Fatalf("Draw() forced slot = %d, want a replacement", batch.Items[9])
+			}
+		default:
+			t.Fatalf("Draw() Forced = %d, want -1 or 9", batch.Forced)
+		}
+		lastCounts[batch.Items[9]]++
+	}
+
+	// 0.9^10 of batches need replacing
+	if rate := float64(forced) / batches; rate < 0.33 || rate > 0.365 {
+		t.Errorf("forced rate = %v, want about 0.349", rate)
+	}
+
+	rates := g.SlotRates()
+	for data, p := range rates[9] {
+		want, _ := p.Float64()
+		if got := float64(lastCounts[data]) / batches; got-want > 0.005 || want-got > 0.005 {
+			t.Errorf("last slot rate of %d = %v, want about %v", data, got, want)
+		}
+	}
+}
+
+func TestGuaranteedBatch_SlotRates(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption(3, 1), NewOption(4, 1))
+	g, err := NewGuaranteedBatch(s, 2, func(d int) bool { return d >= 4 }, nil)
+	if err != nil {
+		t.Fatal("Failed to create GuaranteedBatch:", err)
+	}
+
+	rates := g.SlotRates()
+	if len(rates) != 2 {
+		t.Fatalf("SlotRates() returned %d slots, want 2", len(rates))
+	}
+
+	want := []map[int]*big.Rat{
+		{3: big.NewRat(1, 2), 4: big.NewRat(1, 2)},
+		// The last slot is 3 only if the first slot met the guarantee and it drew 3
+		{3: big.NewRat(1, 4), 4: big.NewRat(3, 4)},
+	}
+	for slot, slotRates := range rates {
+		for data, p := range slotRates {
+			if p.Cmp(want[slot][data]) != 0 {
+				t.Errorf("slot %d rate of %d = %v, want %v", slot, data, p, want[slot][data])
+			}
+		}
+	}
+}
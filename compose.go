@@ -0,0 +1,180 @@
+package weightedoption
+
+import (
+	"iter"
+	"math"
+	"math/big"
+)
+
+// Mix creates a new Selector which selects from the provided component
+// Selectors, choosing each component with a probability proportional to its
+// Option weight. The probabilities of the components' Options are combined
+// exactly, so Data present in several components becomes a single Option. The
+// Options are ordered by where their Data first appears across the components,
+// and InputIndex returns that position. If the combined weights can't be
+// represented in the max integer value for this system's architecture
+// ErrTotalWeightOverflow is returned. Use NewMixture when DataType isn't
+// comparable.
+func Mix[DataType comparable, WeightType WeightConstraint](
+	components ...Option[*Selector[DataType, WeightType], WeightType],
+) (*Selector[DataType, WeightType], error) {
+	components, _, mixWeights, err := prepareOptions(-1, components...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every component's total weight divides the common multiple, giving each
+	// Option an integer weight of mixWeight * weight * (lcm / totalWeight)
+	lcm := big.NewInt(1)
+	for _, c := range components {
+		lcm = lcmInt(lcm, new(big.Int).SetUint64(uint64(c.Data.totalWeight)))
+	}
+
+	var options []DataType
+	var combined []*big.Int
+	positions := make(map[DataType]int)
+	for k, c := range components {
+		factor := new(big.Int).Quo(lcm, new(big.Int).SetUint64(uint64(c.Data.totalWeight)))
+		factor.Mul(factor, new(big.Int).SetUint64(uint64(mixWeights[k])))
+
+		for i, data := range c.Data.options {
+			w := new(big.Int).Mul(factor, new(big.Int).SetUint64(uint64(c.Data.weight(i))))
+			if j, ok := positions[data]; ok {
+				combined[j].Add(combined[j], w)
+				continue
+			}
+			positions[data] = len(options)
+			options = append(options, data)
+			combined = append(combined, w)
+		}
+	}
+
+	weights, err := reduceWeights(combined)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, len(options))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	return newSelector[DataType, WeightType](options, indices, weights)
+}
+
+// reduceWeights divides big integer weights by their greatest common divisor
+// and converts them to uint, returning ErrTotalWeightOverflow if their total
+// still exceeds the max integer value for this system's architecture.
+func reduceWeights(weights []*big.Int) ([]uint, error) {
+	gcd := new(big.Int)
+	for _, w := range weights {
+		gcd.GCD(nil, nil, gcd, w)
+	}
+
+	total := new(big.Int)
+	reduced := make([]uint, len(weights))
+	for i, w := range weights {
+		w.Quo(w, gcd)
+		total.Add(total, w)
+		if total.Cmp(big.NewInt(math.MaxInt)) > 0 {
+			return nil, ErrTotalWeightOverflow
+		}
+		reduced[i] = uint(w.Uint64())
+	}
+	return reduced, nil
+}
+
+// Mixture selects from component Selectors in two stages: first a component
+// is selected by its weight, then an Option is selected from that component.
+// Unlike Mix it works for any DataType, but Data present in several components
+// isn't combined.
+type Mixture[DataType any, WeightType WeightConstraint] struct {
+	components *Selector[*Selector[DataType, WeightType], WeightType]
+}
+
+// NewMixture creates a new Mixture which selects from the provided component
+// Selectors, choosing each component with a probability proportional to its
+// Option weight. The same rules as NewSelector apply to the component weights.
+func NewMixture[DataType any, WeightType WeightConstraint](
+	components ...Option[*Selector[DataType, WeightType], WeightType],
+) (*Mixture[DataType, WeightType], error) {
+	s, err := NewSelector(components...)
+	if err != nil {
+		return nil, err
+	}
+	return &Mixture[DataType, WeightType]{components: s}, nil
+}
+
+// Select returns a single DataType from a component selected by its weight.
+func (m *Mixture[DataType, WeightType]) Select() DataType {
+	return m.components.Select().Select()
+}
+
+// Stream returns an endless sequence of DataType drawn from the Mixture.
+func (m *Mixture[DataType, WeightType]) Stream() iter.Seq[DataType] {
+	return stream(m.Select)
+}
+
+// Take returns a sequence of n DataType drawn from the Mixture.
+func (m *Mixture[DataType, WeightType]) Take(n int) iter.Seq[DataType] {
+	return take(m.Select, n)
+}
+
+// Map creates a new Selector which selects the same Options as s with the same
+// probabilities, but returns their Data transformed by f. f is called once per
+// Option when Map is called. The new Selector shares its weights and random
+// number generator with s.
+func Map[DataType, MappedType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	f func(DataType) MappedType,
+) *Selector[MappedType, WeightType] {
+	options := make([]MappedType, len(s.options))
+	for i, data := range s.options {
+		options[i] = f(data)
+	}
+
+	return &Selector[MappedType, WeightType]{
+		totalWeight:          s.totalWeight,
+		cumulativeWeightSums: s.cumulativeWeightSums,
+		options:              options,
+		indices:              s.indices,
+		rng:                  s.rng,
+		alias:                s.alias,
+	}
+}
+
+// Filter creates a new Selector with only the Options of s for which keep
+// returns true, with their probabilities renormalised. InputIndex keeps
+// referring to the Options s was created from, and the new Selector uses the
+// same algorithm and random number generator as s. If no Options are kept
+// ErrNoValidOptions is returned.
+func Filter[DataType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	keep func(DataType) bool,
+) (*Selector[DataType, WeightType], error) {
+	var (
+		options []DataType
+		indices []int
+		weights []uint
+	)
+	for i, data := range s.options {
+		if keep(data) {
+			options = append(options, data)
+			indices = append(indices, s.indices[i])
+			weights = append(weights, s.weight(i))
+		}
+	}
+
+	filtered, err := newSelector[DataType, WeightType](options, indices, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	// A subset of the weights can't overflow where the full set didn't
+	if s.alias != nil {
+		_ = filtered.buildAliasTable()
+	}
+	filtered.rng = s.rng
+
+	return filtered, nil
+}
@@ -0,0 +1,187 @@
+package weightedoption
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestMix(t *testing.T) {
+	t.Parallel()
+
+	event, err := NewSelector(NewOption('a', 1), NewOption('b', 1))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+	standard, err := NewSelector(NewOption('a', 1), NewOption('c', 3))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+	huge, err := NewSelector(NewOption('d', math.MaxInt-1), NewOption('e', 1))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	tests := []struct {
+		name        string
+		components  []Option[*Selector[rune, int], int]
+		wantOptions []rune
+		wantWeights []uint
+		wantErr     error
+	}{
+		{
+			name:       "no components",
+			components: []Option[*Selector[rune, int], int]{},
+			wantErr:    ErrNoValidOptions,
+		},
+		{
+			name:        "single component",
+			components:  []Option[*Selector[rune, int], int]{NewOption(standard, 5)},
+			wantOptions: []rune{'a', 'c'},
+			wantWeights: []uint{1, 3},
+		},
+		{
+			name: "shared data is combined exactly",
+			components: []Option[*Selector[rune, int], int]{
+				NewOption(event, 1),
+				NewOption(standard, 9),
+			},
+			wantOptions: []rune{'a', 'b', 'c'},
+			wantWeights: []uint{11, 2, 27},
+		},
+		{
+			name: "zero weight component is ignored",
+			components: []Option[*Selector[rune, int], int]{
+				NewOption(event, 0),
+				NewOption(standard, 9),
+			},
+			wantOptions: []rune{'a', 'c'},
+			wantWeights: []uint{1, 3},
+		},
+		{
+			name: "combined weights overflow",
+			components: []Option[*Selector[rune, int], int]{
+				NewOption(huge, 1),
+				NewOption(standard, 2),
+			},
+			wantErr: ErrTotalWeightOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := Mix(tt.components...)
+			if err != tt.wantErr {
+				t.Fatalf("Mix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !slices.Equal(s.options, tt.wantOptions) {
+				t.Errorf("Mix() options = %c, want %c", s.options, tt.wantOptions)
+			}
+			if got := weightsOf(s); !slices.Equal(got, tt.wantWeights) {
+				t.Errorf("Mix() weights = %v, want %v", got, tt.wantWeights)
+			}
+		})
+	}
+}
+
+func TestMixture_Select(t *testing.T) {
+	t.Parallel()
+
+	low, err := NewSelector(NewOption([]int{1}, 1))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+	high, err := NewSelector(NewOption([]int{2}, 1))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	m, err := NewMixture(NewOption(low, 1), NewOption(high, 9))
+	if err != nil {
+		t.Fatal("Failed to create Mixture:", err)
+	}
+
+	counts := make(map[int]int)
+	for data := range m.Take(testIterations) {
+		counts[data[0]]++
+	}
+	if counts[1] >= counts[2] {
+		t.Errorf("lower weighted component selected %d times, higher weighted component %d times", counts[1], counts[2])
+	}
+
+	if _, err := NewMixture[[]int, int](); err != ErrNoValidOptions {
+		t.Errorf("NewMixture() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	s, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	mapped := Map(s, strconv.Itoa)
+	if !slices.Equal(weightsOf(mapped), weightsOf(s)) {
+		t.Errorf("Map() weights = %v, want %v", weightsOf(mapped), weightsOf(s))
+	}
+
+	counts := make(map[int]int)
+	for data := range mapped.Take(testIterations) {
+		i, err := strconv.Atoi(data)
+		if err != nil {
+			t.Fatal("Map() returned unmapped data:", data)
+		}
+		counts[i]++
+	}
+	verifyFrequencyCounts(t, counts, options)
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	cs := []Option[rune, int]{
+		NewOption('a', 1),
+		NewOption('b', 0),
+		NewOption('c', 2),
+		NewOption('d', 3),
+	}
+	for _, algorithm := range []Algorithm{AlgorithmBinarySearch, AlgorithmAlias} {
+		s, err := NewSelectorWith(cs, WithAlgorithm(algorithm))
+		if err != nil {
+			t.Fatal("Failed to create Selector:", err)
+		}
+
+		filtered, err := Filter(s, func(r rune) bool { return r != 'c' })
+		if err != nil {
+			t.Fatal("Filter() error:", err)
+		}
+		if want := []rune{'a', 'd'}; !slices.Equal(filtered.options, want) {
+			t.Errorf("Filter() options = %c, want %c", filtered.options, want)
+		}
+		if want := []uint{1, 3}; !slices.Equal(weightsOf(filtered), want) {
+			t.Errorf("Filter() weights = %v, want %v", weightsOf(filtered), want)
+		}
+		if want := []int{0, 3}; !slices.Equal(filtered.InputIndices(), want) {
+			t.Errorf("Filter() InputIndices() = %v, want %v", filtered.InputIndices(), want)
+		}
+		if (filtered.alias != nil) != (algorithm == AlgorithmAlias) {
+			t.Errorf("Filter() did not keep algorithm %d", algorithm)
+		}
+		for data := range filtered.Take(1000) {
+			if data == 'c' {
+				t.Fatal("Filter() selected a filtered out Option")
+			}
+		}
+
+		if _, err := Filter(s, func(rune) bool { return false }); err != ErrNoValidOptions {
+			t.Errorf("Filter() error = %v, wantErr %v", err, ErrNoValidOptions)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Package dice parses and rolls dice in standard notation, such as "3d6+2",
+// "4d6kh3" or "2d20kl1", and computes the exact distribution of their totals.
+//
+// A dice term is written NdS, rolling N dice with S sides, where N defaults to
+// 1 and "d%" is a d100. It may be followed by "!" to make each die explode,
+// rolling again and adding whenever it rolls its maximum, and then by one of
+// khK or kK to keep the K highest dice, klK to keep the K lowest, dlK to drop
+// the K lowest or dhK to drop the K highest. Terms and integer constants are
+// joined with "+" and "-".
+package dice
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultMaxExplosions = 5
+	percentileSides      = 100
+	// maxCount and maxSides bound the work of a single roll. Distributions
+	// are bounded separately by maxCost.
+	maxCount = 1000
+	maxSides = 10000
+)
+
+// ErrSyntax is returned by Parse for notation which isn't valid.
+var ErrSyntax = errors.New("invalid dice notation")
+
+// ParseOption configures an Expr created by Parse.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	maxExplosions int
+}
+
+// WithMaxExplosions sets how many times an exploding die may explode before
+// its last roll is kept as it is, which keeps its distribution finite. Both
+// rolls and distributions follow this limit, so they always agree. The
+// default is 5.
+func WithMaxExplosions(n int) ParseOption {
+	return func(c *parseConfig) {
+		c.maxExplosions = max(n, 0)
+	}
+}
+
+// term is a dice term or, when sides is 0, an integer constant.
+type term struct {
+	negative bool
+	constant int
+	count    int
+	sides    int
+	explode  bool
+	// keep is the number of dice kept, the highest unless keepLowest
+	keep       int
+	keepLowest bool
+}
+
+// Expr is parsed dice notation which can be rolled or analysed.
+type Expr struct {
+	notation      string
+	terms         []term
+	maxExplosions int
+
+	// once computes reduced, the weights of the total, or weightsErr
+	once       sync.Once
+	reduced    weights
+	weightsErr error
+}
+
+// Parse parses dice notation, configured by the provided ParseOptions. It is
+// case-insensitive and ignores whitespace. Each term may have at most 1000
+// dice of at most 10000 sides, though Distribution may reject far fewer.
+func Parse(notation string, cfg ...ParseOption) (*Expr, error) {
+	c := parseConfig{maxExplosions: defaultMaxExplosions}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+
+	p := &parser{s: strings.ToLower(strings.Join(strings.Fields(notation), ""))}
+	if p.s == "" {
+		return nil, fmt.Errorf("%w: empty", ErrSyntax)
+	}
+
+	e := &Expr{notation: notation, maxExplosions: c.maxExplosions}
+	negative := p.accept("-")
+	if !negative {
+		p.accept("+")
+	}
+	for {
+		t, err := p.term()
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrSyntax, notation, err)
+		}
+		t.negative = negative
+		e.terms = append(e.terms, t)
+
+		if p.done() {
+			return e, nil
+		}
+		switch p.next() {
+		case '+':
+			negative = false
+		case '-':
+			negative = true
+		default:
+			return nil, fmt.Errorf("%w %q: unexpected %q at %d", ErrSyntax, notation, p.s[p.i-1], p.i-1)
+		}
+	}
+}
+
+// String returns the notation the Expr was parsed from.
+func (e *Expr) String() string {
+	return e.notation
+}
+
+// parser scans normalised dice notation.
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *parser) next() byte {
+	c := p.peek()
+	p.i++
+	return c
+}
+
+// accept consumes prefix if the notation continues with it.
+func (p *parser) accept(prefix string) bool {
+	if strings.HasPrefix(p.s[p.i:], prefix) {
+		p.i += len(prefix)
+		return true
+	}
+	return false
+}
+
+// number consumes a non-negative integer, reporting false if there is none.
+func (p *parser) number() (int, bool, error) {
+	start := p.i
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.i++
+	}
+	if start == p.i {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(p.s[start:p.i])
+	if err != nil {
+		return 0, false, fmt.Errorf("number %s is too large", p.s[start:p.i])
+	}
+	return n, true, nil
+}
+
+// term consumes a dice term or an integer constant.
+func (p *parser) term() (term, error) {
+	count, hasCount, err := p.number()
+	if err != nil {
+		return term{}, err
+	}
+	if !p.accept("d") {
+		if !hasCount {
+			return term{}, fmt.Errorf("expected a number or dice at %d", p.i)
+		}
+		return term{constant: count}, nil
+	}
+	if !hasCount {
+		count = 1
+	}
+
+	sides, ok, err := p.number()
+	switch {
+	case err != nil:
+		return term{}, err
+	case !ok && p.accept("%"):
+		sides = percentileSides
+	case !ok:
+		return term{}, fmt.Errorf("expected sides at %d", p.i)
+	}
+	if count < 1 || count > maxCount || sides < 1 || sides > maxSides {
+		return term{}, fmt.Errorf("%dd%d is out of range", count, sides)
+	}
+
+	t := term{count: count, sides: sides, keep: count, explode: p.accept("!")}
+	return t, p.keep(&t)
+}
+
+// keep consumes an optional keep or drop modifier of t.
+func (p *parser) keep(t *term) error {
+	var drop bool
+	switch {
+	case p.accept("kl"):
+		t.keepLowest = true
+	case p.accept("kh"), p.accept("k"):
+	case p.accept("dl"):
+		drop = true
+	case p.accept("dh"):
+		drop, t.keepLowest = true, true
+	default:
+		return nil
+	}
+
+	n, ok, err := p.number()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("expected a number of dice at %d", p.i)
+	}
+	if drop {
+		n = t.count - n
+	}
+	if n < 1 || n > t.count {
+		return fmt.Errorf("can't keep %d of %d dice", n, t.count)
+	}
+	t.keep = n
+	return nil
+}
+
+// Die is the result of rolling a single die.
+type Die struct {
+	// Rolls is every face rolled, more than one when the die exploded.
+	Rolls []int
+	// Total is the sum of Rolls.
+	Total int
+	// Kept reports whether the die counts towards the total.
+	Kept bool
+}
+
+// Result is the result of rolling an Expr.
+type Result struct {
+	Total int
+	// Dice has the Dice rolled for each dice term, in order.
+	Dice [][]Die
+}
+
+// Roll rolls the Expr, drawing from src, or from the global random number
+// generator if src is nil.
+func (e *Expr) Roll(src rand.Source) Result {
+	uintN := rand.UintN
+	if src != nil {
+		uintN = rand.New(src).UintN
+	}
+
+	var res Result
+	for _, t := range e.terms {
+		value := t.constant
+		if t.sides > 0 {
+			dice := t.roll(uintN, e.maxExplosions)
+			res.Dice = append(res.Dice, dice)
+			value = 0
+			for _, d := range dice {
+				if d.Kept {
+					value += d.Total
+				}
+			}
+		}
+		if t.negative {
+			value = -value
+		}
+		res.Total += value
+	}
+	return res
+}
+
+// roll rolls the dice of t and marks which of them are kept.
+func (t *term) roll(uintN func(uint) uint, maxExplosions int) []Die {
+	dice := make([]Die, t.count)
+	for i := range dice {
+		d := &dice[i]
+		for explosions := 0; ; explosions++ {
+			face := int(uintN(uint(t.sides))) + 1
+			d.Rolls = append(d.Rolls, face)
+			d.Total += face
+			if !t.explode || face != t.sides || explosions == maxExplosions {
+				break
+			}
+		}
+	}
+
+	// Keep the highest or lowest dice, preferring earlier dice on ties
+	order := make([]int, len(dice))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		if t.keepLowest {
+			return cmp.Compare(dice[a].Total, dice[b].Total)
+		}
+		return cmp.Compare(dice[b].Total, dice[a].Total)
+	})
+	for _, i := range order[:t.keep] {
+		dice[i].Kept = true
+	}
+	return dice
+}
@@ -0,0 +1,165 @@
+package dice
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func mustParse(t *testing.T, notation string, cfg ...ParseOption) *Expr {
+	t.Helper()
+	e, err := Parse(notation, cfg...)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", notation, err)
+	}
+	return e
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		notation string
+		want     []term
+		wantErr  bool
+	}{
+		{notation: "3d6+2", want: []term{{count: 3, sides: 6, keep: 3}, {constant: 2}}},
+		{notation: " 4D6 kh3 ", want: []term{{count: 4, sides: 6, keep: 3}}},
+		{notation: "4d6k3", want: []term{{count: 4, sides: 6, keep: 3}}},
+		{notation: "2d20kl1", want: []term{{count: 2, sides: 20, keep: 1, keepLowest: true}}},
+		{notation: "4d6dl1", want: []term{{count: 4, sides: 6, keep: 3}}},
+		{notation: "4d6dh1", want: []term{{count: 4, sides: 6, keep: 3, keepLowest: true}}},
+		{notation: "d%", want: []term{{count: 1, sides: 100, keep: 1}}},
+		{notation: "2d6!kh1", want: []term{{count: 2, sides: 6, keep: 1, explode: true}}},
+		{notation: "-1d4+d8-3", want: []term{
+			{negative: true, count: 1, sides: 4, keep: 1},
+			{count: 1, sides: 8, keep: 1},
+			{negative: true, constant: 3},
+		}},
+		{notation: "", wantErr: true},
+		{notation: "d", wantErr: true},
+		{notation: "3d", wantErr: true},
+		{notation: "0d6", wantErr: true},
+		{notation: "2d0", wantErr: true},
+		{notation: "1001d6", wantErr: true},
+		{notation: "2d6kh3", wantErr: true},
+		{notation: "2d6dl2", wantErr: true},
+		{notation: "2d6kh", wantErr: true},
+		{notation: "2d6+", wantErr: true},
+		{notation: "2d6*2", wantErr: true},
+		{notation: "99999999999999999999d6", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			t.Parallel()
+			e, err := Parse(tt.notation)
+			if tt.wantErr {
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Parse(%q) error = %v, wantErr %v", tt.notation, err, ErrSyntax)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.notation, err)
+			}
+			if len(e.terms) != len(tt.want) {
+				t.Fatalf("Parse(%q) terms = %+v, want %+v", tt.notation, e.terms, tt.want)
+			}
+			for i := range tt.want {
+				if e.terms[i] != tt.want[i] {
+					t.Errorf("Parse(%q) term %d = %+v, want %+v", tt.notation, i, e.terms[i], tt.want[i])
+				}
+			}
+			if e.String() != tt.notation {
+				t.Errorf("String() = %q, want %q", e.String(), tt.notation)
+			}
+		})
+	}
+}
+
+func TestExpr_Roll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		notation string
+		cfg      []ParseOption
+		min, max int
+	}{
+		{notation: "3d6+2", min: 5, max: 20},
+		{notation: "4d6kh3", min: 3, max: 18},
+		{notation: "2d20kl1", min: 1, max: 20},
+		{notation: "d%", min: 1, max: 100},
+		{notation: "1d4-1d4", min: -3, max: 3},
+		{notation: "1d6!", cfg: []ParseOption{WithMaxExplosions(1)}, min: 1, max: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			t.Parallel()
+			e := mustParse(t, tt.notation, tt.cfg...)
+			seen := make(map[int]bool)
+			for range 20_000 {
+				res := e.Roll(nil)
+				if res.Total < tt.min || res.Total > tt.max {
+					t.Fatalf("Roll() = %d, want between %d and %d", res.Total, tt.min, tt.max)
+				}
+				seen[res.Total] = true
+			}
+			if !seen[tt.min] || !seen[tt.max] {
+				t.Errorf("Roll() never rolled %d or %d", tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestExpr_RollDice(t *testing.T) {
+	t.Parallel()
+
+	e := mustParse(t, "4d6!dl1+1", WithMaxExplosions(2))
+	for range 1000 {
+		res := e.Roll(nil)
+		if len(res.Dice) != 1 || len(res.Dice[0]) != 4 {
+			t.Fatalf("Roll() dice = %v, want one term of 4 dice", res.Dice)
+		}
+
+		kept, total, lowest := 0, 1, -1
+		for i, d := range res.Dice[0] {
+			if len(d.Rolls) > 3 {
+				t.Fatalf("die %d exploded %d times, want at most 2", i, len(d.Rolls)-1)
+			}
+			if d.Kept {
+				kept++
+				total += d.Total
+			} else {
+				lowest = d.Total
+			}
+		}
+		if kept != 3 || total != res.Total {
+			t.Fatalf("Roll() kept %d dice totalling %d, want 3 totalling %d", kept, total, res.Total)
+		}
+		for _, d := range res.Dice[0] {
+			if d.Kept && d.Total < lowest {
+				t.Fatalf("Roll() dropped %d but kept %d", lowest, d.Total)
+			}
+		}
+	}
+}
+
+func TestExpr_RollSource(t *testing.T) {
+	t.Parallel()
+
+	e := mustParse(t, "8d6!kh4+d%")
+	roll := func() []int {
+		src := rand.NewPCG(9, 10)
+		totals := make([]int, 50)
+		for i := range totals {
+			totals[i] = e.Roll(src).Total
+		}
+		return totals
+	}
+	a, b := roll(), roll()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("rolls with the same source differ at %d: %d and %d", i, a[i], b[i])
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package dice
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// maxCost bounds the estimated cost of computing a distribution, in
+// multiplications of machine words, which keeps it to around a second.
+const maxCost = 2.5e7
+
+// ErrTooComplex is returned by Distribution and Selector when the distribution
+// would cost too much to compute, such as for dozens of dice with hundreds of
+// sides or for dice which may explode many times.
+var ErrTooComplex = errors.New("dice distribution too complex to compute")
+
+// weights maps each possible total to its relative weight.
+type weights map[int]*big.Int
+
+// add adds weight to the weight of v, taking ownership of weight.
+func (w weights) add(v int, weight *big.Int) {
+	if prev, ok := w[v]; ok {
+		prev.Add(prev, weight)
+		return
+	}
+	w[v] = weight
+}
+
+// convolve returns the weights of the sum of independent values with weights
+// a and b.
+func convolve(a, b weights) weights {
+	sum := make(weights, len(a)+len(b))
+	for va, wa := range a {
+		for vb, wb := range b {
+			sum.add(va+vb, new(big.Int).Mul(wa, wb))
+		}
+	}
+	return sum
+}
+
+// dieWeights returns the weights of a single die's total. An exploding die's
+// outcome after j explosions has probability 1/sides^(j+1), so scaling every
+// probability by sides^(maxExplosions+1) makes every weight an integer.
+func (t *term) dieWeights(maxExplosions int) weights {
+	w := make(weights)
+	if !t.explode {
+		for face := 1; face <= t.sides; face++ {
+			w[face] = big.NewInt(1)
+		}
+		return w
+	}
+
+	sides := big.NewInt(int64(t.sides))
+	for j := 0; j <= maxExplosions; j++ {
+		// Before the last explosion the highest face explodes instead
+		faces := t.sides - 1
+		if j == maxExplosions {
+			faces = t.sides
+		}
+		weight := new(big.Int).Exp(sides, big.NewInt(int64(maxExplosions-j)), nil)
+		for face := 1; face <= faces; face++ {
+			w[j*t.sides+face] = weight
+		}
+	}
+	return w
+}
+
+// cost returns an estimate of the number of possible values of the term and
+// of the cost of computing their weights, following how weights computes them.
+func (t *term) cost(maxExplosions int) (values, cost float64) {
+	if t.sides == 0 {
+		return 1, 0
+	}
+
+	// Die values run from 1 to high, and each die's weight has about bits bits
+	faces, bits := float64(t.sides), math.Log2(float64(t.sides))
+	if t.explode {
+		faces *= float64(maxExplosions + 1)
+		bits *= float64(maxExplosions + 1)
+	}
+	high := faces
+	words := float64(t.count)*bits/64 + 1
+
+	if t.keep == t.count {
+		for rolled := range t.count {
+			cost += (float64(rolled)*(high-1) + 1) * faces
+		}
+		return float64(t.count)*(high-1) + 1, cost * words
+	}
+	// Tabulating binomials and powers of each face's weight
+	cost = float64(t.count)*float64(t.count)/2 + faces*float64(t.count)*2
+	for placed := range t.keep {
+		sums := float64(placed)*(high-1) + 1
+		cost += faces * sums * float64(t.count-placed+1)
+	}
+	return float64(t.keep)*(high-1) + 1, cost * words
+}
+
+// weights returns the weights of the term's value.
+func (t *term) weights(maxExplosions int) weights {
+	if t.sides == 0 {
+		return weights{t.constant: big.NewInt(1)}
+	}
+
+	die := t.dieWeights(maxExplosions)
+	var w weights
+	if t.keep == t.count {
+		w = weights{0: big.NewInt(1)}
+		for range t.count {
+			w = convolve(w, die)
+		}
+	} else {
+		w = t.keptWeights(die)
+	}
+
+	if !t.negative {
+		return w
+	}
+	negated := make(weights, len(w))
+	for v, weight := range w {
+		negated[-v] = weight
+	}
+	return negated
+}
+
+// keptWeights returns the weights of the sum of the kept dice. It goes through
+// the faces from the first kept to the last, choosing how many of the dice not
+// yet placed show each face, so the first t.keep dice placed are the ones
+// kept. Only the number of dice placed and the sum kept so far are tracked,
+// never which dice were kept, and once t.keep dice are placed the rest may
+// show any later face.
+func (t *term) keptWeights(die weights) weights {
+	faces := make([]int, 0, len(die))
+	for face := range die {
+		faces = append(faces, face)
+	}
+	slices.Sort(faces)
+	if !t.keepLowest {
+		slices.Reverse(faces)
+	}
+
+	// binomial[n][c] is the number of ways to choose c of n dice
+	binomial := make([][]*big.Int, t.count+1)
+	for n := range binomial {
+		binomial[n] = make([]*big.Int, n+1)
+		binomial[n][0], binomial[n][n] = big.NewInt(1), big.NewInt(1)
+		for c := 1; c < n; c++ {
+			binomial[n][c] = new(big.Int).Add(binomial[n-1][c-1], binomial[n-1][c])
+		}
+	}
+
+	// later is the total weight of the faces after the current one
+	later := new(big.Int)
+	for _, weight := range die {
+		later.Add(later, weight)
+	}
+
+	// placed[n] holds the weights of the sum kept once n < t.keep dice are placed
+	w := make(weights)
+	placed := make([]weights, t.keep)
+	placed[0] = weights{0: big.NewInt(1)}
+	powers, laterPowers := make([]*big.Int, t.count+1), make([]*big.Int, t.count+1)
+	for _, face := range faces {
+		later.Sub(later, die[face])
+		powers[0], laterPowers[0] = big.NewInt(1), big.NewInt(1)
+		for c := 1; c <= t.count; c++ {
+			powers[c] = new(big.Int).Mul(powers[c-1], die[face])
+			laterPowers[c] = new(big.Int).Mul(laterPowers[c-1], later)
+		}
+
+		next := make([]weights, t.keep)
+		for n, sums := range placed {
+			left := t.count - n
+			for sum, weight := range sums {
+				for c := 0; c <= left; c++ {
+					weight := new(big.Int).Mul(weight, binomial[left][c])
+					weight.Mul(weight, powers[c])
+					if n+c >= t.keep {
+						// The dice after the kept ones show later faces
+						w.add(sum+(t.keep-n)*face, weight.Mul(weight, laterPowers[left-c]))
+						continue
+					}
+					if next[n+c] == nil {
+						next[n+c] = make(weights)
+					}
+					next[n+c].add(sum+c*face, weight)
+				}
+			}
+		}
+		placed = next
+	}
+	return w
+}
+
+// weights returns the reduced weights of the Expr's total, computing them
+// once. If they would cost too much to compute ErrTooComplex is returned.
+func (e *Expr) weights() (weights, error) {
+	e.once.Do(func() {
+		values, cost := 1.0, 0.0
+		for i := range e.terms {
+			termValues, termCost := e.terms[i].cost(e.maxExplosions)
+			cost += termCost + values*termValues
+			values += termValues - 1
+		}
+		if cost > maxCost {
+			e.weightsErr = ErrTooComplex
+			return
+		}
+
+		w := weights{0: big.NewInt(1)}
+		for i := range e.terms {
+			w = convolve(w, e.terms[i].weights(e.maxExplosions))
+		}
+
+		gcd := new(big.Int)
+		for _, weight := range w {
+			gcd.GCD(nil, nil, gcd, weight)
+		}
+		for _, weight := range w {
+			weight.Quo(weight, gcd)
+		}
+		e.reduced = w
+	})
+	return e.reduced, e.weightsErr
+}
+
+// Outcome is a possible total of an Expr and the exact probability of rolling it.
+type Outcome struct {
+	Total       int
+	Probability *big.Rat
+}
+
+// Distribution returns every possible total of the Expr with its exact
+// probability, in ascending order of total. The weights behind it are
+// computed once and shared with Selector. Computing them takes time
+// proportional to the number of dice multiplied by the number of possible
+// totals, and when keeping dice also by the number of dice and of faces. If
+// that would cost too much ErrTooComplex is returned.
+func (e *Expr) Distribution() ([]Outcome, error) {
+	w, err := e.weights()
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	for _, weight := range w {
+		total.Add(total, weight)
+	}
+
+	outcomes := make([]Outcome, 0, len(w))
+	for v, weight := range w {
+		outcomes = append(outcomes, Outcome{Total: v, Probability: new(big.Rat).SetFrac(weight, total)})
+	}
+	slices.SortFunc(outcomes, func(a, b Outcome) int {
+		return cmp.Compare(a.Total, b.Total)
+	})
+	return outcomes, nil
+}
+
+// Selector returns a Selector of every possible total of the Expr, in
+// ascending order, weighted by exactly how often it is rolled, for example to
+// display odds or to draw totals without rolling each die. It reuses the
+// weights computed for Distribution. If they would cost too much to compute
+// ErrTooComplex is returned, and if they don't fit in an integer
+// weightedoption.ErrTotalWeightOverflow is returned.
+func (e *Expr) Selector() (*weightedoption.Selector[int, uint], error) {
+	w, err := e.weights()
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]weightedoption.Option[int, uint], 0, len(w))
+	for v, weight := range w {
+		if !weight.IsUint64() || weight.Uint64() > math.MaxInt {
+			return nil, weightedoption.ErrTotalWeightOverflow
+		}
+		opts = append(opts, weightedoption.NewOption(v, uint(weight.Uint64())))
+	}
+	slices.SortFunc(opts, func(a, b weightedoption.Option[int, uint]) int {
+		return cmp.Compare(a.Data, b.Data)
+	})
+	return weightedoption.NewSelector(opts...)
+}
@@ -0,0 +1,202 @@
+package dice
+
+import (
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+func TestExpr_Distribution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		notation string
+		cfg      []ParseOption
+		outcomes int
+		want     map[int]string
+	}{
+		{notation: "2d6", outcomes: 11, want: map[int]string{2: "1/36", 7: "1/6", 12: "1/36"}},
+		{notation: "3d6+2", outcomes: 16, want: map[int]string{5: "1/216", 20: "1/216", 12: "1/8"}},
+		{notation: "4d6kh3", outcomes: 16, want: map[int]string{3: "1/1296", 18: "21/1296"}},
+		{notation: "4d6dl1", outcomes: 16, want: map[int]string{3: "1/1296", 18: "21/1296"}},
+		{notation: "2d20kh1", outcomes: 20, want: map[int]string{20: "39/400", 1: "1/400"}},
+		{notation: "2d20kl1", outcomes: 20, want: map[int]string{1: "39/400", 20: "1/400"}},
+		{notation: "d%", outcomes: 100, want: map[int]string{1: "1/100", 100: "1/100"}},
+		{notation: "1d4-1d4", outcomes: 7, want: map[int]string{0: "1/4", -3: "1/16"}},
+		{notation: "1d6!", cfg: []ParseOption{WithMaxExplosions(1)}, outcomes: 11, want: map[int]string{5: "1/6", 6: "0", 7: "1/36", 12: "1/36"}},
+		{notation: "2d6!kh1", cfg: []ParseOption{WithMaxExplosions(0)}, outcomes: 6, want: map[int]string{6: "11/36"}},
+		{notation: "7", outcomes: 1, want: map[int]string{7: "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			t.Parallel()
+			dist, err := mustParse(t, tt.notation, tt.cfg...).Distribution()
+			if err != nil {
+				t.Fatal("Distribution() error:", err)
+			}
+			if len(dist) != tt.outcomes {
+				t.Errorf("Distribution() has %d outcomes, want %d", len(dist), tt.outcomes)
+			}
+
+			probabilities := make(map[int]*big.Rat)
+			sum := new(big.Rat)
+			for i, o := range dist {
+				if i > 0 && o.Total <= dist[i-1].Total {
+					t.Fatalf("Distribution() is not in ascending order at %d", i)
+				}
+				probabilities[o.Total] = o.Probability
+				sum.Add(sum, o.Probability)
+			}
+			if sum.Cmp(big.NewRat(1, 1)) != 0 {
+				t.Errorf("Distribution() probabilities sum to %s, want 1", sum)
+			}
+
+			for total, p := range tt.want {
+				want, _ := new(big.Rat).SetString(p)
+				got := probabilities[total]
+				if got == nil {
+					got = new(big.Rat)
+				}
+				if got.Cmp(want) != 0 {
+					t.Errorf("P(%d) = %s, want %s", total, got.RatString(), want.RatString())
+				}
+			}
+		})
+	}
+}
+
+func TestExpr_Selector(t *testing.T) {
+	t.Parallel()
+
+	s, err := mustParse(t, "2d6").Selector()
+	if err != nil {
+		t.Fatal("Selector() error:", err)
+	}
+	if s.Len() != 11 {
+		t.Fatalf("Selector() has %d options, want 11", s.Len())
+	}
+
+	counts := make(map[int]int)
+	const n = 360_000
+	for range n {
+		counts[s.Select()]++
+	}
+	if got := float64(counts[7]) / n; got < 0.16 || got > 0.173 {
+		t.Errorf("Selector() selected 7 with frequency %.4f, want 1/6", got)
+	}
+
+	// 10 exploding d20 have weights far beyond an integer
+	_, err = mustParse(t, "10d20!", WithMaxExplosions(5)).Selector()
+	if !errors.Is(err, weightedoption.ErrTotalWeightOverflow) {
+		t.Errorf("Selector() error = %v, wantErr %v", err, weightedoption.ErrTotalWeightOverflow)
+	}
+}
+
+func TestExpr_DistributionTooComplex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		notation string
+		cfg      []ParseOption
+		wantErr  error
+	}{
+		{notation: "100d100", wantErr: ErrTooComplex},
+		{notation: "1000d10000", wantErr: ErrTooComplex},
+		{notation: "1d10000+1d10000", wantErr: ErrTooComplex},
+		{notation: "20d20!", cfg: []ParseOption{WithMaxExplosions(100)}, wantErr: ErrTooComplex},
+		{notation: "50d20"},
+		{notation: "4d10!kh3"},
+		{notation: "5d20!kh3"},
+		{notation: "2d10000kh1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			t.Parallel()
+			e := mustParse(t, tt.notation, tt.cfg...)
+			if _, err := e.Distribution(); err != tt.wantErr {
+				t.Errorf("Distribution() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := e.Selector(); tt.wantErr != nil && err != tt.wantErr {
+				t.Errorf("Selector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTerm_KeptWeights(t *testing.T) {
+	t.Parallel()
+
+	for _, notation := range []string{"4d6kh3", "5d4kl2", "3d6dh1", "4d3!kh2", "3d4!kl2", "6d2kh5"} {
+		t.Run(notation, func(t *testing.T) {
+			t.Parallel()
+			e := mustParse(t, notation, WithMaxExplosions(2))
+			term := &e.terms[0]
+			die := term.dieWeights(e.maxExplosions)
+
+			// Enumerate every roll of the dice, keeping from a sorted copy
+			want := make(weights)
+			rolls := [][]int{nil}
+			for range term.count {
+				var next [][]int
+				for _, roll := range rolls {
+					for face := range die {
+						next = append(next, append(slices.Clone(roll), face))
+					}
+				}
+				rolls = next
+			}
+			for _, roll := range rolls {
+				weight := big.NewInt(1)
+				for _, face := range roll {
+					weight.Mul(weight, die[face])
+				}
+				slices.Sort(roll)
+				kept := roll[len(roll)-term.keep:]
+				if term.keepLowest {
+					kept = roll[:term.keep]
+				}
+				sum := 0
+				for _, face := range kept {
+					sum += face
+				}
+				want.add(sum, weight)
+			}
+
+			got := term.keptWeights(die)
+			if len(got) != len(want) {
+				t.Errorf("keptWeights() has %d values, want %d", len(got), len(want))
+			}
+			for v, weight := range want {
+				if got[v] == nil || got[v].Cmp(weight) != 0 {
+					t.Errorf("keptWeights()[%d] = %v, want %v", v, got[v], weight)
+				}
+			}
+		})
+	}
+}
+
+func TestExpr_DistributionMatchesRoll(t *testing.T) {
+	t.Parallel()
+
+	e := mustParse(t, "3d4!kl2", WithMaxExplosions(2))
+	dist, err := e.Distribution()
+	if err != nil {
+		t.Fatal("Distribution() error:", err)
+	}
+	counts := make(map[int]int)
+	const n = 200_000
+	for range n {
+		counts[e.Roll(nil).Total]++
+	}
+
+	for _, o := range dist {
+		want, _ := o.Probability.Float64()
+		got := float64(counts[o.Total]) / n
+		if diff := got - want; diff < -0.005 || diff > 0.005 {
+			t.Errorf("rolled %d with frequency %.4f, want %.4f", o.Total, got, want)
+		}
+	}
+}
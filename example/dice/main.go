@@ -3,37 +3,32 @@ package main
 import (
 	"fmt"
 
-	"github.com/eljamo/weightedoption/v3"
+	"github.com/eljamo/weightedoption/v3/dice"
 )
 
-func getOptions(sides int) []weightedoption.Option[int, int] {
-	options := make([]weightedoption.Option[int, int], sides)
-	for i := 0; i < sides; i++ {
-		options[i] = weightedoption.NewOption(i+1, 1)
-	}
-
-	return options
-}
-
-func rollDice(sides int) (int, error) {
-	selector, err := weightedoption.NewSelector(
-		getOptions(sides)...,
-	)
-	if err != nil {
-		return 0, err
-	}
-
-	return selector.Select(), nil
-}
-
 func main() {
-	diceSides := []int{4, 6, 8, 10, 12, 20}
+	notations := []string{"d4", "d6", "d8", "d10", "d12", "d20", "d%", "3d6+2", "4d6kh3", "2d20kh1", "2d20kl1", "1d6!"}
 
-	for _, sides := range diceSides {
-		result, err := rollDice(sides)
+	for _, notation := range notations {
+		expr, err := dice.Parse(notation)
 		if err != nil {
 			panic(err)
 		}
-		fmt.Printf("Rolled a d%d: %d\n", sides, result)
+		fmt.Printf("Rolled %s: %d\n", expr, expr.Roll(nil).Total)
+	}
+
+	// Show the odds of each total when rolling with advantage
+	expr, err := dice.Parse("2d20kh1")
+	if err != nil {
+		panic(err)
+	}
+	dist, err := expr.Distribution()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\nOdds for %s:\n", expr)
+	for _, outcome := range dist {
+		p, _ := outcome.Probability.Float64()
+		fmt.Printf("%2d: %5.2f%%\n", outcome.Total, p*100)
 	}
 }
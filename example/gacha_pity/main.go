@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"iter"
 	"math/rand/v2"
 
 	"github.com/eljamo/weightedoption/v3"
@@ -22,9 +23,7 @@ type GachaBanner struct {
 	selector       *weightedoption.Selector[string, float64]
 }
 
-func (b *GachaBanner) pull(userId string) string {
-	// Pull an item from the selector
-	drop := b.selector.Select()
+func (b *GachaBanner) applyPity(drop, userId string) string {
 	pityCount := b.pityCounterMap[userId] + 1
 
 	if pityCount >= b.pityThreshold {
@@ -40,15 +39,14 @@ func (b *GachaBanner) pull(userId string) string {
 	return drop
 }
 
-func (b *GachaBanner) PullN(n int, userId string) []string {
-	drops := make([]string, n)
-
-	for i := 0; i < n; i++ {
-		drop := b.pull(userId)
-		drops[i] = drop
+func (b *GachaBanner) PullN(n int, userId string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for drop := range b.selector.Take(n) {
+			if !yield(b.applyPity(drop, userId)) {
+				return
+			}
+		}
 	}
-
-	return drops
 }
 
 func NewGachaBanner(pool []weightedoption.Option[string, float64], pityThreshold int, pityDrop string) (*GachaBanner, error) {
@@ -116,9 +114,7 @@ func main() {
 	// Run until the main drop is pulled, on the 90th it'll be guaranteed
 	for !pityPulled {
 		timesToPull := oneOrTen()
-		drops := banner.PullN(timesToPull, userId)
-
-		for _, drop := range drops {
+		for drop := range banner.PullN(timesToPull, userId) {
 			allDrops = append(allDrops, drop)
 
 			count++
@@ -0,0 +1,69 @@
+package weightedoption
+
+import (
+	"slices"
+)
+
+// SelectExcluding returns a single DataType from Selector.Options, ignoring every
+// Option for which exclude returns true. exclude is called once per Option
+// with its index in the Options the Selector was created from and its Data.
+// The remaining Options are selected with their probabilities renormalised.
+// If every Option is excluded ErrNoValidOptions is returned.
+func (s Selector[DataType, WeightType]) SelectExcluding(
+	exclude func(i int, data DataType) bool,
+) (DataType, error) {
+	var positions []int
+	for i, data := range s.options {
+		if exclude(s.indices[i], data) {
+			positions = append(positions, i)
+		}
+	}
+	return s.selectExcludingPositions(positions)
+}
+
+// SelectExcludingIndices returns a single DataType from Selector.Options,
+// ignoring the Options at the provided indexes in the Options the Selector was
+// created from. Indexes of Options which aren't in the Selector are ignored.
+// With WithMergeDuplicates that includes the index of a duplicate merged into
+// an earlier Option, so the merged Option is only excluded by the index of its
+// first occurrence. It takes O(k log n + k log k) time for k indexes and n
+// Options, to look up and sort the indexes and then walk over them. If every
+// Option is excluded ErrNoValidOptions is returned.
+func (s Selector[DataType, WeightType]) SelectExcludingIndices(indices ...int) (DataType, error) {
+	positions := make([]int, 0, len(indices))
+	for _, index := range indices {
+		if i, ok := slices.BinarySearch(s.indices, index); ok {
+			positions = append(positions, i)
+		}
+	}
+	slices.Sort(positions)
+	return s.selectExcludingPositions(slices.Compact(positions))
+}
+
+// selectExcludingPositions selects from the Options not at the provided
+// ascending positions. Instead of rejection sampling or rebuilding the running
+// total weights, it draws from the total weight left after the exclusions and
+// maps the draw back onto the full running totals by stepping over each
+// excluded Option's range which lies before it.
+func (s Selector[DataType, WeightType]) selectExcludingPositions(positions []int) (DataType, error) {
+	remaining := s.totalWeight
+	for _, p := range positions {
+		remaining -= s.weight(p)
+	}
+	if remaining == 0 {
+		var zero DataType
+		return zero, ErrNoValidOptions
+	}
+
+	r := s.uintN(remaining)
+	for _, p := range positions {
+		w := s.weight(p)
+		if r < s.cumulativeWeightSums[p]-w {
+			break
+		}
+		r += w
+	}
+
+	i, _ := slices.BinarySearch(s.cumulativeWeightSums, r+1)
+	return s.options[i], nil
+}
@@ -0,0 +1,126 @@
+package weightedoption
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelector_SelectExcluding(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	s, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	excluded := map[int]bool{1: true, 4: true, 5: true, 10: true}
+	counts := make(map[int]int)
+	for i := 0; i < testIterations; i++ {
+		data, err := s.SelectExcluding(func(_ int, data int) bool { return excluded[data] })
+		if err != nil {
+			t.Fatal("SelectExcluding() error:", err)
+		}
+		if excluded[data] {
+			t.Fatalf("SelectExcluding() = %d, which is excluded", data)
+		}
+		counts[data]++
+	}
+
+	var remaining []Option[int, int]
+	for _, opt := range options {
+		if !excluded[opt.Data] {
+			remaining = append(remaining, opt)
+		}
+	}
+	verifyFrequencyCounts(t, counts, remaining)
+
+	_, err = s.SelectExcluding(func(int, int) bool { return true })
+	if err != ErrNoValidOptions {
+		t.Errorf("SelectExcluding() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+}
+
+func TestSelector_SelectExcludingIndices(t *testing.T) {
+	t.Parallel()
+
+	cs := []Option[rune, int]{
+		NewOption('a', 1),
+		NewOption('b', 0),
+		NewOption('c', 2),
+		NewOption('d', 3),
+		NewOption('e', 4),
+	}
+	s, err := NewSelector(cs...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	tests := []struct {
+		name     string
+		indices  []int
+		allowed  string
+		wantErr  error
+		wantSeen int
+	}{
+		{name: "no exclusions", indices: nil, allowed: "acde", wantSeen: 4},
+		{name: "first and last", indices: []int{4, 0}, allowed: "cd", wantSeen: 2},
+		{name: "duplicate and unknown indexes", indices: []int{3, 3, 1, 99, -1}, allowed: "ace", wantSeen: 3},
+		{name: "everything", indices: []int{0, 2, 3, 4}, wantErr: ErrNoValidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			seen := make(map[rune]bool)
+			for i := 0; i < 10_000; i++ {
+				data, err := s.SelectExcludingIndices(tt.indices...)
+				if err != tt.wantErr {
+					t.Fatalf("SelectExcludingIndices() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				seen[data] = true
+			}
+			for data := range seen {
+				if !strings.ContainsRune(tt.allowed, data) {
+					t.Errorf("SelectExcludingIndices() = %c, want one of %q", data, tt.allowed)
+				}
+			}
+			if len(seen) != tt.wantSeen {
+				t.Errorf("SelectExcludingIndices() selected %d distinct Options, want %d", len(seen), tt.wantSeen)
+			}
+		})
+	}
+}
+
+func TestSelector_SelectExcludingIndicesMerged(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewSelectorWith(
+		[]Option[rune, int]{NewOption('a', 1), NewOption('b', 1), NewOption('a', 1)},
+		WithMergeDuplicates(),
+	)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	// The index of the merged-away duplicate doesn't exclude the merged Option
+	seen := make(map[rune]bool)
+	for i := 0; i < 1_000; i++ {
+		data, err := s.SelectExcludingIndices(2)
+		if err != nil {
+			t.Fatal("SelectExcludingIndices() error:", err)
+		}
+		seen[data] = true
+	}
+	if !seen['a'] || !seen['b'] {
+		t.Errorf("SelectExcludingIndices(2) selected %v, want a and b", seen)
+	}
+
+	for i := 0; i < 1_000; i++ {
+		if data, err := s.SelectExcludingIndices(0); err != nil || data != 'b' {
+			t.Fatalf("SelectExcludingIndices(0) = %c, %v, want b, nil", data, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package weightedoption
+
+import "math/bits"
+
+// fenwick is a Fenwick tree, or binary indexed tree, of weights. It updates a
+// weight and finds the position of a running total weight in O(log n) time,
+// for Selectors whose weights change as Options are selected.
+type fenwick struct {
+	tree  []uint
+	total uint
+}
+
+// newFenwick creates a new fenwick holding weights in O(n) time.
+func newFenwick(weights []uint) *fenwick {
+	f := &fenwick{tree: make([]uint, len(weights)+1)}
+	for i, w := range weights {
+		f.total += w
+		j := i + 1
+		f.tree[j] += w
+		if parent := j + j&-j; parent < len(f.tree) {
+			f.tree[parent] += f.tree[j]
+		}
+	}
+	return f
+}
+
+// add adds delta to the weight at position i.
+func (f *fenwick) add(i int, delta uint) {
+	f.total += delta
+	for j := i + 1; j < len(f.tree); j += j & -j {
+		f.tree[j] += delta
+	}
+}
+
+// sub subtracts delta, which must not exceed it, from the weight at position i.
+func (f *fenwick) sub(i int, delta uint) {
+	f.total -= delta
+	for j := i + 1; j < len(f.tree); j += j & -j {
+		f.tree[j] -= delta
+	}
+}
+
+// get returns the weight at position i.
+func (f *fenwick) get(i int) uint {
+	return f.prefix(i+1) - f.prefix(i)
+}
+
+// prefix returns the total weight of the positions before i.
+func (f *fenwick) prefix(i int) uint {
+	var sum uint
+	for j := i; j > 0; j -= j & -j {
+		sum += f.tree[j]
+	}
+	return sum
+}
+
+// find returns the position whose range of the running total weight contains
+// r, which must be less than the total weight.
+func (f *fenwick) find(r uint) int {
+	pos := 0
+	for step := 1 << (bits.Len(uint(len(f.tree)-1)) - 1); step > 0; step >>= 1 {
+		if next := pos + step; next < len(f.tree) && f.tree[next] <= r {
+			pos = next
+			r -= f.tree[next]
+		}
+	}
+	return pos
+}
@@ -0,0 +1,38 @@
+package weightedoption
+
+import (
+	"testing"
+)
+
+func TestFenwick(t *testing.T) {
+	t.Parallel()
+
+	weights := []uint{3, 0, 1, 4, 1, 5, 9, 2, 6}
+	f := newFenwick(weights)
+
+	check := func(weights []uint) {
+		t.Helper()
+		var total uint
+		for i, w := range weights {
+			for r := total; r < total+w; r++ {
+				if got := f.find(r); got != i {
+					t.Errorf("find(%d) = %d, want %d", r, got, i)
+				}
+			}
+			total += w
+		}
+		if f.total != total {
+			t.Errorf("total = %d, want %d", f.total, total)
+		}
+	}
+
+	check(weights)
+
+	f.sub(3, 4)
+	weights[3] = 0
+	f.add(1, 2)
+	weights[1] = 2
+	f.sub(8, 1)
+	weights[8] = 5
+	check(weights)
+}
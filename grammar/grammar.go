@@ -0,0 +1,251 @@
+// Package grammar expands Tracery-style text templates such as
+// "#greeting#, #name.capitalize#!", where each symbol picks one of its
+// weighted expansions with a weightedoption.Selector.
+//
+// Within a template, #symbol# is replaced by an expansion of the symbol, which
+// is itself expanded, and #symbol.mod1.mod2# then applies each Modifier in
+// turn. An action [name:template] expands template once and binds the result
+// to name for the rest of the generation, so later #name# tags reuse it; an
+// action can also open a tag, as in #[hero:#name#]story#. A backslash escapes
+// the next character.
+package grammar
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+const defaultMaxDepth = 32
+
+var (
+	// ErrUnknownSymbol is returned when a template refers to a symbol which is
+	// neither a rule nor bound by an action.
+	ErrUnknownSymbol = errors.New("unknown symbol")
+	// ErrUnknownModifier is returned when a template applies a Modifier which
+	// isn't registered.
+	ErrUnknownModifier = errors.New("unknown modifier")
+	// ErrRecursionLimit is returned when expanding a template nests deeper than
+	// the maximum depth, which usually means a rule refers to itself.
+	ErrRecursionLimit = errors.New("recursion limit reached")
+	// ErrSyntax is returned for a template with an unclosed tag or action, or an
+	// action without a colon.
+	ErrSyntax = errors.New("syntax error")
+)
+
+// Modifier transforms the expansion of a tag.
+type Modifier func(string) string
+
+// Option configures a Grammar created by New or Load.
+type Option func(*grammarConfig)
+
+type grammarConfig struct {
+	maxDepth  int
+	modifiers map[string]Modifier
+	source    rand.Source
+}
+
+// WithMaxDepth sets how deeply expansions may nest before ErrRecursionLimit is
+// returned. The default is 32.
+func WithMaxDepth(n int) Option {
+	return func(c *grammarConfig) {
+		c.maxDepth = n
+	}
+}
+
+// WithModifier registers fn as the Modifier called name, replacing any
+// built-in Modifier with the same name.
+func WithModifier(name string, fn Modifier) Option {
+	return func(c *grammarConfig) {
+		c.modifiers[name] = fn
+	}
+}
+
+// WithSource makes the Grammar draw from src instead of the global random
+// number generator, so a seeded src makes generation reproducible. As src is
+// usually not safe for concurrent use, neither is the Grammar.
+func WithSource(src rand.Source) Option {
+	return func(c *grammarConfig) {
+		c.source = src
+	}
+}
+
+// Grammar expands templates using a set of rules. Unless it was created with
+// WithSource it is safe for concurrent use.
+type Grammar struct {
+	rules     map[string]*weightedoption.Selector[string, float64]
+	modifiers map[string]Modifier
+	maxDepth  int
+}
+
+// New creates a new Grammar from rules mapping each symbol to its weighted
+// expansions, configured by the provided Options. The same rules as
+// weightedoption.NewSelector apply to each symbol's expansions, and an error
+// naming the symbol is returned if they are invalid.
+func New(rules map[string][]weightedoption.Option[string, float64], cfg ...Option) (*Grammar, error) {
+	c := grammarConfig{maxDepth: defaultMaxDepth, modifiers: defaultModifiers()}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+
+	g := &Grammar{
+		rules:     make(map[string]*weightedoption.Selector[string, float64], len(rules)),
+		modifiers: c.modifiers,
+		maxDepth:  c.maxDepth,
+	}
+	var selectorCfg []weightedoption.SelectorOption
+	if c.source != nil {
+		selectorCfg = append(selectorCfg, weightedoption.WithSource(c.source))
+	}
+	for symbol, expansions := range rules {
+		s, err := weightedoption.NewSelectorWith(expansions, selectorCfg...)
+		if err != nil {
+			return nil, fmt.Errorf("symbol %q: %w", symbol, err)
+		}
+		g.rules[symbol] = s
+	}
+	return g, nil
+}
+
+// Expand returns template with every tag and action expanded.
+func (g *Grammar) Expand(template string) (string, error) {
+	e := &expansion{grammar: g, vars: make(map[string]string)}
+	return e.expand(template, 0)
+}
+
+// Flatten returns an expansion of symbol, the same as expanding "#symbol#".
+func (g *Grammar) Flatten(symbol string) (string, error) {
+	return g.Expand("#" + symbol + "#")
+}
+
+// expansion holds the variables bound during a single call to Expand.
+type expansion struct {
+	grammar *Grammar
+	vars    map[string]string
+}
+
+// expand expands every tag and action in text, which is nested depth deep.
+func (e *expansion) expand(text string, depth int) (string, error) {
+	if depth > e.grammar.maxDepth {
+		return "", ErrRecursionLimit
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i+1 < len(text) {
+				i++
+				b.WriteByte(text[i])
+			}
+		case '[':
+			end, err := closing(text, i)
+			if err != nil {
+				return "", err
+			}
+			if err := e.action(text[i+1:end], depth); err != nil {
+				return "", err
+			}
+			i = end
+		case '#':
+			end, err := closing(text, i)
+			if err != nil {
+				return "", err
+			}
+			expanded, err := e.tag(text[i+1:end], depth)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+			i = end
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// closing returns the index of the character which closes the tag or action
+// opened at start, skipping escaped characters and nested actions.
+func closing(text string, start int) (int, error) {
+	open := text[start]
+	nested := 0
+	for i := start + 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\':
+			i++
+		case c == '[':
+			nested++
+		case c == ']' && nested > 0:
+			nested--
+		case nested == 0 && (open == '[' && c == ']' || open == '#' && c == '#'):
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unclosed %q in %q", ErrSyntax, open, text)
+}
+
+// action binds the expansion of the template in an action to its name.
+func (e *expansion) action(content string, depth int) error {
+	name, template, ok := strings.Cut(content, ":")
+	if !ok {
+		return fmt.Errorf("%w: action %q has no colon", ErrSyntax, content)
+	}
+
+	value, err := e.expand(template, depth+1)
+	if err != nil {
+		return err
+	}
+	e.vars[name] = value
+	return nil
+}
+
+// tag runs any actions opening a tag, expands its symbol and applies its
+// Modifiers.
+func (e *expansion) tag(content string, depth int) (string, error) {
+	for strings.HasPrefix(content, "[") {
+		end, err := closing(content, 0)
+		if err != nil {
+			return "", err
+		}
+		if err := e.action(content[1:end], depth); err != nil {
+			return "", err
+		}
+		content = content[end+1:]
+	}
+
+	parts := strings.Split(content, ".")
+	symbol, mods := parts[0], parts[1:]
+	if symbol == "" {
+		return "", nil
+	}
+
+	expanded, err := e.symbol(symbol, depth)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range mods {
+		mod, ok := e.grammar.modifiers[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrUnknownModifier, name)
+		}
+		expanded = mod(expanded)
+	}
+	return expanded, nil
+}
+
+// symbol returns the value bound to symbol, or else an expansion of its rule.
+func (e *expansion) symbol(symbol string, depth int) (string, error) {
+	if value, ok := e.vars[symbol]; ok {
+		return value, nil
+	}
+
+	rule, ok := e.grammar.rules[symbol]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	}
+	return e.expand(rule.Select(), depth+1)
+}
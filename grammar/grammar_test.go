@@ -0,0 +1,154 @@
+package grammar
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+type testRules = map[string][]weightedoption.Option[string, float64]
+
+func one(texts ...string) []weightedoption.Option[string, float64] {
+	opts := make([]weightedoption.Option[string, float64], len(texts))
+	for i, text := range texts {
+		opts[i] = weightedoption.NewOption(text, 1.0)
+	}
+	return opts
+}
+
+func mustGrammar(t *testing.T, rules testRules, cfg ...Option) *Grammar {
+	t.Helper()
+	g, err := New(rules, cfg...)
+	if err != nil {
+		t.Fatal("Failed to create Grammar:", err)
+	}
+	return g
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(testRules{"empty": {{Data: "never", Weight: 0}}})
+	if !errors.Is(err, weightedoption.ErrNoValidOptions) || !strings.Contains(err.Error(), `"empty"`) {
+		t.Errorf("New() error = %v, want ErrNoValidOptions naming the symbol", err)
+	}
+}
+
+func TestGrammar_Expand(t *testing.T) {
+	t.Parallel()
+
+	g := mustGrammar(t, testRules{
+		"greeting": one("hello"),
+		"name":     one("ada"),
+		"animal":   one("fox"),
+		"sentence": one("#greeting.capitalize#, #name.capitalize#!"),
+		"story":    one("#[hero:#name#]tale#"),
+		"tale":     one("#hero# met #animal.a#. #hero.capitalize# liked #animal.s#."),
+	}, WithModifier("shout", strings.ToUpper))
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  error
+	}{
+		{name: "plain", template: "no tags", want: "no tags"},
+		{name: "nested rules", template: "#sentence#", want: "Hello, Ada!"},
+		{name: "chained modifiers", template: "#animal.s.capitalize#", want: "Foxes"},
+		{name: "custom modifier", template: "#name.shout#", want: "ADA"},
+		{name: "action in tag", template: "#story#", want: "ada met a fox. Ada liked foxes."},
+		{name: "standalone action", template: "[pet:#animal#]my #pet#", want: "my fox"},
+		{name: "variable shadows rule", template: "[name:bob]#name#", want: "bob"},
+		{name: "escape", template: `\#name\# and \[x\]`, want: "#name# and [x]"},
+		{name: "empty tag", template: "a##b", want: "ab"},
+		{name: "unknown symbol", template: "#missing#", wantErr: ErrUnknownSymbol},
+		{name: "unknown modifier", template: "#name.missing#", wantErr: ErrUnknownModifier},
+		{name: "unclosed tag", template: "#name", wantErr: ErrSyntax},
+		{name: "unclosed action", template: "[x:y", wantErr: ErrSyntax},
+		{name: "action without colon", template: "[x]", wantErr: ErrSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := g.Expand(tt.template)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expand(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrammar_ExpandBindingsArePerCall(t *testing.T) {
+	t.Parallel()
+
+	g := mustGrammar(t, testRules{"name": one("ada")})
+	if _, err := g.Expand("[name:bob]"); err != nil {
+		t.Fatal("Expand() error:", err)
+	}
+	if got, _ := g.Flatten("name"); got != "ada" {
+		t.Errorf("Flatten() = %q after a binding in another call, want ada", got)
+	}
+}
+
+func TestGrammar_ExpandRecursionLimit(t *testing.T) {
+	t.Parallel()
+
+	g := mustGrammar(t, testRules{"loop": one("again #loop#")}, WithMaxDepth(5))
+	if _, err := g.Flatten("loop"); !errors.Is(err, ErrRecursionLimit) {
+		t.Errorf("Flatten() error = %v, wantErr %v", err, ErrRecursionLimit)
+	}
+}
+
+func TestGrammar_ExpandWeights(t *testing.T) {
+	t.Parallel()
+
+	g := mustGrammar(t, testRules{
+		"word": {{Data: "common", Weight: 0.9}, {Data: "rare", Weight: 0.1}},
+	})
+
+	common := 0
+	const n = 20_000
+	for range n {
+		word, err := g.Flatten("word")
+		if err != nil {
+			t.Fatal("Flatten() error:", err)
+		}
+		if word == "common" {
+			common++
+		}
+	}
+	if got := float64(common) / n; got < 0.88 || got > 0.92 {
+		t.Errorf("common was expanded %.3f of the time, want 0.9", got)
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	rules := testRules{
+		"adjective": one("red", "green", "blue", "quiet", "loud"),
+		"noun":      one("cat", "hill", "river", "lamp"),
+		"phrase":    one("#adjective# #noun#", "#noun.s#"),
+	}
+	generate := func() string {
+		g := mustGrammar(t, rules, WithSource(rand.NewPCG(7, 8)))
+		var parts []string
+		for range 20 {
+			phrase, err := g.Flatten("phrase")
+			if err != nil {
+				t.Fatal("Flatten() error:", err)
+			}
+			parts = append(parts, phrase)
+		}
+		return strings.Join(parts, ", ")
+	}
+	if a, b := generate(), generate(); a != b {
+		t.Errorf("grammars with the same seed generated %q and %q", a, b)
+	}
+}
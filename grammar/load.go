@@ -0,0 +1,70 @@
+package grammar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// jsonExpansion is an expansion in a JSON grammar, either a plain string with
+// a weight of 1 or an object with text and weight.
+type jsonExpansion struct {
+	Text   string  `json:"text"`
+	Weight float64 `json:"weight"`
+}
+
+func (e *jsonExpansion) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		e.Weight = 1
+		return json.Unmarshal(data, &e.Text)
+	}
+
+	type plain jsonExpansion
+	p := plain{Weight: 1}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*e = jsonExpansion(p)
+	return nil
+}
+
+// jsonRule is a rule in a JSON grammar, either a single expansion or a list.
+type jsonRule []jsonExpansion
+
+func (r *jsonRule) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`[`)) {
+		return json.Unmarshal(data, (*[]jsonExpansion)(r))
+	}
+
+	var e jsonExpansion
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	*r = jsonRule{e}
+	return nil
+}
+
+// Load creates a new Grammar from a JSON object read from r which maps each
+// symbol to its expansions, configured by the provided Options. As in
+// Tracery, a symbol's expansions are a list of strings, each with a weight of
+// 1, or a single string. A list element can also be an object such as
+// {"text": "howdy", "weight": 0.5} to give it another weight.
+func Load(r io.Reader, cfg ...Option) (*Grammar, error) {
+	var rules map[string]jsonRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("decoding grammar: %w", err)
+	}
+
+	opts := make(map[string][]weightedoption.Option[string, float64], len(rules))
+	for symbol, rule := range rules {
+		expansions := make([]weightedoption.Option[string, float64], len(rule))
+		for i, e := range rule {
+			expansions[i] = weightedoption.NewOption(e.Text, e.Weight)
+		}
+		opts[symbol] = expansions
+	}
+	return New(opts, cfg...)
+}
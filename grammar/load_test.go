@@ -0,0 +1,44 @@
+package grammar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	g, err := Load(strings.NewReader(`{
+		"origin": "#greeting#, #name#!",
+		"greeting": [{"text": "howdy", "weight": 3}, {"text": "hello", "weight": 0}],
+		"name": ["sam"]
+	}`))
+	if err != nil {
+		t.Fatal("Load() error:", err)
+	}
+	if got, err := g.Flatten("origin"); err != nil || got != "howdy, sam!" {
+		t.Errorf("Flatten() = %q, %v, want howdy, sam!", got, err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "malformed", input: `{"origin": [`},
+		{name: "wrong type", input: `{"origin": 3}`},
+		{name: "no valid expansions", input: `{"origin": []}`, wantErr: weightedoption.ErrNoValidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Load(strings.NewReader(tt.input))
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
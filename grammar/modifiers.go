@@ -0,0 +1,88 @@
+package grammar
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultModifiers returns the built-in Modifiers, which follow Tracery's
+// English modifiers.
+func defaultModifiers() map[string]Modifier {
+	return map[string]Modifier{
+		"capitalize":    Capitalize,
+		"capitalizeAll": CapitalizeAll,
+		"s":             Pluralize,
+		"a":             Article,
+		"ed":            PastTense,
+	}
+}
+
+// Capitalize returns s with its first letter in upper case. It is the
+// "capitalize" Modifier.
+func Capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// CapitalizeAll returns s with the first letter of every word in upper case.
+// It is the "capitalizeAll" Modifier.
+func CapitalizeAll(s string) string {
+	var b strings.Builder
+	start := true
+	for _, r := range s {
+		if start {
+			r = unicode.ToUpper(r)
+		}
+		start = unicode.IsSpace(r)
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Pluralize returns the plural of the English noun s, e.g. "foxes" for "fox"
+// and "skies" for "sky". It is the "s" Modifier.
+func Pluralize(s string) string {
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "sh"), strings.HasSuffix(s, "ch"),
+		strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"):
+		return s + "es"
+	case consonantY(s):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// Article returns s preceded by "a" or "an", depending on whether it starts
+// with a vowel. It is the "a" Modifier.
+func Article(s string) string {
+	r, _ := utf8.DecodeRuneInString(s)
+	if strings.ContainsRune("aeiouAEIOU", r) {
+		return "an " + s
+	}
+	return "a " + s
+}
+
+// PastTense returns the past tense of the regular English verb s, e.g.
+// "jumped" for "jump" and "tried" for "try". It is the "ed" Modifier.
+func PastTense(s string) string {
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(s, "e"):
+		return s + "d"
+	case consonantY(s):
+		return s[:len(s)-1] + "ied"
+	}
+	return s + "ed"
+}
+
+// consonantY reports whether s ends with a y which follows a consonant.
+func consonantY(s string) bool {
+	return len(s) >= 2 && s[len(s)-1] == 'y' && !strings.ContainsRune("aeiouAEIOU", rune(s[len(s)-2]))
+}
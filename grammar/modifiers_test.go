@@ -0,0 +1,35 @@
+package grammar
+
+import "testing"
+
+func TestModifiers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		mod  Modifier
+		in   string
+		want string
+	}{
+		{name: "capitalize", mod: Capitalize, in: "éclair time", want: "Éclair time"},
+		{name: "capitalize empty", mod: Capitalize, in: "", want: ""},
+		{name: "capitalizeAll", mod: CapitalizeAll, in: "the old  mill", want: "The Old  Mill"},
+		{name: "plural", mod: Pluralize, in: "cat", want: "cats"},
+		{name: "plural sibilant", mod: Pluralize, in: "church", want: "churches"},
+		{name: "plural consonant y", mod: Pluralize, in: "sky", want: "skies"},
+		{name: "plural vowel y", mod: Pluralize, in: "day", want: "days"},
+		{name: "article consonant", mod: Article, in: "dragon", want: "a dragon"},
+		{name: "article vowel", mod: Article, in: "owl", want: "an owl"},
+		{name: "past tense", mod: PastTense, in: "walk", want: "walked"},
+		{name: "past tense e", mod: PastTense, in: "bake", want: "baked"},
+		{name: "past tense consonant y", mod: PastTense, in: "carry", want: "carried"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.mod(tt.in); got != tt.want {
+				t.Errorf("%s(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+			}
+		})
+	}
+}
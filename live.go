@@ -0,0 +1,128 @@
+package weightedoption
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is an immutable, versioned Selector published by a LiveSelector.
+type Snapshot[DataType any, WeightType WeightConstraint] struct {
+	version  uint64
+	options  []Option[DataType, WeightType]
+	selector *Selector[DataType, WeightType]
+}
+
+// Version returns the version of the Snapshot. The first Snapshot published by
+// a LiveSelector has version 1 and each subsequent Snapshot increments it by 1.
+func (s *Snapshot[DataType, WeightType]) Version() uint64 {
+	return s.version
+}
+
+// Options returns a copy of the Options the Snapshot was built from.
+func (s *Snapshot[DataType, WeightType]) Options() []Option[DataType, WeightType] {
+	return slices.Clone(s.options)
+}
+
+// Selector returns the Selector built from the Snapshot's Options.
+func (s *Snapshot[DataType, WeightType]) Selector() *Selector[DataType, WeightType] {
+	return s.selector
+}
+
+// LiveSelector is a Selector whose Options can be replaced or patched while
+// other goroutines keep selecting from it. Readers never block: each call to
+// Select draws from the most recently published Snapshot, which is swapped in
+// atomically. Writers are serialised with each other.
+type LiveSelector[DataType any, WeightType WeightConstraint] struct {
+	mu        sync.Mutex
+	current   atomic.Pointer[Snapshot[DataType, WeightType]]
+	onPublish func(*Snapshot[DataType, WeightType])
+}
+
+// NewLiveSelector creates a new LiveSelector and publishes the provided Options
+// as version 1. The same rules as NewSelector apply to the Options.
+func NewLiveSelector[DataType any, WeightType WeightConstraint](
+	opts ...Option[DataType, WeightType],
+) (*LiveSelector[DataType, WeightType], error) {
+	ls := &LiveSelector[DataType, WeightType]{}
+	if err := ls.Replace(opts...); err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// OnPublish registers fn to be called, while holding the writer lock, each time
+// a new Snapshot is published. Passing nil removes the callback.
+func (ls *LiveSelector[DataType, WeightType]) OnPublish(fn func(*Snapshot[DataType, WeightType])) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.onPublish = fn
+}
+
+// Snapshot returns the current Snapshot.
+func (ls *LiveSelector[DataType, WeightType]) Snapshot() *Snapshot[DataType, WeightType] {
+	return ls.current.Load()
+}
+
+// Version returns the version of the current Snapshot.
+func (ls *LiveSelector[DataType, WeightType]) Version() uint64 {
+	return ls.current.Load().version
+}
+
+// Replace publishes a new Snapshot built from the provided Options. If the
+// Options are invalid the current Snapshot is kept and the error is returned.
+func (ls *LiveSelector[DataType, WeightType]) Replace(opts ...Option[DataType, WeightType]) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.publish(slices.Clone(opts))
+}
+
+// Patch publishes a new Snapshot built from the Options returned by fn, which
+// receives a copy of the current Options and may modify it in place. The
+// returned Options are copied, so fn may keep and later modify them without
+// changing the published Snapshot. If they are invalid the current Snapshot
+// is kept and the error is returned.
+func (ls *LiveSelector[DataType, WeightType]) Patch(
+	fn func(opts []Option[DataType, WeightType]) []Option[DataType, WeightType],
+) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.publish(slices.Clone(fn(ls.current.Load().Options())))
+}
+
+// publish must be called while holding ls.mu.
+func (ls *LiveSelector[DataType, WeightType]) publish(opts []Option[DataType, WeightType]) error {
+	selector, err := NewSelector(opts...)
+	if err != nil {
+		return err
+	}
+
+	var version uint64 = 1
+	if prev := ls.current.Load(); prev != nil {
+		version = prev.version + 1
+	}
+
+	snap := &Snapshot[DataType, WeightType]{
+		version:  version,
+		options:  opts,
+		selector: selector,
+	}
+	ls.current.Store(snap)
+
+	if ls.onPublish != nil {
+		ls.onPublish(snap)
+	}
+	return nil
+}
+
+// Select returns a single DataType from the current Snapshot.
+func (ls *LiveSelector[DataType, WeightType]) Select() DataType {
+	return ls.current.Load().selector.Select()
+}
+
+// Stream returns an endless sequence of DataType. Each value is drawn from the
+// Snapshot that is current at the time it is drawn.
+func (ls *LiveSelector[DataType, WeightType]) Stream() iter.Seq[DataType] {
+	return stream(ls.Select)
+}
@@ -0,0 +1,146 @@
+package weightedoption
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewLiveSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cs      []Option[rune, int]
+		wantErr error
+	}{
+		{
+			name:    "no options",
+			cs:      []Option[rune, int]{},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name:    "nominal case",
+			cs:      []Option[rune, int]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 2}},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ls, err := NewLiveSelector(tt.cs...)
+			if err != tt.wantErr {
+				t.Errorf("NewLiveSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && ls.Version() != 1 {
+				t.Errorf("NewLiveSelector() version = %d, want 1", ls.Version())
+			}
+		})
+	}
+}
+
+func TestLiveSelector_Replace(t *testing.T) {
+	t.Parallel()
+
+	ls, err := NewLiveSelector(NewOption('a', 1))
+	if err != nil {
+		t.Fatal("Failed to create LiveSelector:", err)
+	}
+
+	var published []uint64
+	ls.OnPublish(func(s *Snapshot[rune, int]) {
+		published = append(published, s.Version())
+	})
+
+	if err := ls.Replace(NewOption('b', 1)); err != nil {
+		t.Fatal("Replace() error:", err)
+	}
+	if got := ls.Select(); got != 'b' {
+		t.Errorf("Select() = %c, want b", got)
+	}
+
+	if err := ls.Replace(NewOption('c', 0)); err != ErrNoValidOptions {
+		t.Errorf("Replace() error = %v, want %v", err, ErrNoValidOptions)
+	}
+	if got := ls.Version(); got != 2 {
+		t.Errorf("Version() after failed Replace = %d, want 2", got)
+	}
+	if got := ls.Select(); got != 'b' {
+		t.Errorf("Select() after failed Replace = %c, want b", got)
+	}
+
+	if len(published) != 1 || published[0] != 2 {
+		t.Errorf("OnPublish versions = %v, want [2]", published)
+	}
+}
+
+func TestLiveSelector_Patch(t *testing.T) {
+	t.Parallel()
+
+	ls, err := NewLiveSelector(NewOption('a', 1), NewOption('b', 0))
+	if err != nil {
+		t.Fatal("Failed to create LiveSelector:", err)
+	}
+
+	before := ls.Snapshot()
+	var kept []Option[rune, int]
+	err = ls.Patch(func(opts []Option[rune, int]) []Option[rune, int] {
+		opts[0].Weight = 0
+		opts[1].Weight = 1
+		kept = opts
+		return opts
+	})
+	if err != nil {
+		t.Fatal("Patch() error:", err)
+	}
+
+	// Modifying the returned Options afterwards doesn't change the Snapshot
+	kept[1].Weight = 5
+	if got := ls.Snapshot().Options()[1].Weight; got != 1 {
+		t.Errorf("Snapshot Options()[1].Weight after modifying the patched Options = %d, want 1", got)
+	}
+
+	if got := ls.Select(); got != 'b' {
+		t.Errorf("Select() = %c, want b", got)
+	}
+	if got := before.Selector().Select(); got != 'a' {
+		t.Errorf("old Snapshot Select() = %c, want a", got)
+	}
+	if got := before.Options()[0].Weight; got != 1 {
+		t.Errorf("old Snapshot Options()[0].Weight = %d, want 1", got)
+	}
+}
+
+func TestLiveSelector_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	ls, err := NewLiveSelector(NewOption(0, 1))
+	if err != nil {
+		t.Fatal("Failed to create LiveSelector:", err)
+	}
+
+	const writes = 100
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < testIterations/100; j++ {
+				if got := ls.Select(); got < 0 || got > writes {
+					t.Errorf("Select() = %d, want value in [0, %d]", got, writes)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= writes; i++ {
+		if err := ls.Replace(NewOption(i, 1)); err != nil {
+			t.Fatal("Replace() error:", err)
+		}
+	}
+	wg.Wait()
+
+	if got := ls.Version(); got != writes+1 {
+		t.Errorf("Version() = %d, want %d", got, writes+1)
+	}
+}
@@ -0,0 +1,115 @@
+package markov
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// ErrInvalidModel is returned when decoding a Model whose transitions refer to
+// tokens or states it doesn't have.
+var ErrInvalidModel = errors.New("invalid model")
+
+// encodedModel is the JSON form of a Model. Token ids index Tokens from 1, and
+// 0 marks the start or end of a sequence.
+type encodedModel struct {
+	Order       int                 `json:"order"`
+	Tokens      []string            `json:"tokens"`
+	Transitions []encodedTransition `json:"transitions"`
+}
+
+type encodedTransition struct {
+	State []int       `json:"state"`
+	Next  []nextCount `json:"next"`
+}
+
+type nextCount struct {
+	Token int  `json:"token"`
+	Count uint `json:"count"`
+}
+
+// MarshalJSON encodes the Model's tokens and transition counts. The encoding is
+// the same every time for the same Model.
+func (m *Model) MarshalJSON() ([]byte, error) {
+	enc := encodedModel{Order: m.order, Tokens: m.tokens[1:]}
+	for key, opts := range m.transitions {
+		t := encodedTransition{State: decodeStateKey(key)}
+		for _, opt := range opts {
+			t.Next = append(t.Next, nextCount{Token: opt.Data, Count: opt.Weight})
+		}
+		enc.Transitions = append(enc.Transitions, t)
+	}
+	slices.SortFunc(enc.Transitions, func(a, b encodedTransition) int {
+		return slices.Compare(a.State, b.State)
+	})
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a Model encoded by MarshalJSON and rebuilds its
+// Selectors. If the transitions are inconsistent an error wrapping
+// ErrInvalidModel is returned.
+func (m *Model) UnmarshalJSON(data []byte) error {
+	var enc encodedModel
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	if enc.Order < 1 {
+		return fmt.Errorf("%w: order %d is less than 1", ErrInvalidModel, enc.Order)
+	}
+
+	tokens := append([]string{""}, enc.Tokens...)
+	transitions := make(map[string][]weightedoption.Option[int, uint], len(enc.Transitions))
+	for _, t := range enc.Transitions {
+		if err := validateTransition(t, enc.Order, len(tokens)); err != nil {
+			return err
+		}
+		opts := make([]weightedoption.Option[int, uint], len(t.Next))
+		for i, next := range t.Next {
+			opts[i] = weightedoption.NewOption(next.Token, next.Count)
+		}
+		transitions[stateKey(t.State)] = opts
+	}
+
+	decoded, err := newModel(enc.Order, tokens, transitions)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidModel, err)
+	}
+	*m = *decoded
+	return nil
+}
+
+// validateTransition checks that a transition only refers to known tokens and
+// has a state of the right length.
+func validateTransition(t encodedTransition, order, tokens int) error {
+	if len(t.State) != order {
+		return fmt.Errorf("%w: state %v is not of order %d", ErrInvalidModel, t.State, order)
+	}
+	for _, id := range t.State {
+		if id < 0 || id >= tokens {
+			return fmt.Errorf("%w: state %v has unknown token %d", ErrInvalidModel, t.State, id)
+		}
+	}
+	for _, next := range t.Next {
+		if next.Token < 0 || next.Token >= tokens {
+			return fmt.Errorf("%w: state %v has unknown next token %d", ErrInvalidModel, t.State, next.Token)
+		}
+	}
+	return nil
+}
+
+// decodeStateKey decodes a state encoded by stateKey.
+func decodeStateKey(key string) []int {
+	var state []int
+	r := strings.NewReader(key)
+	for r.Len() > 0 {
+		// The key was encoded by stateKey, so it is always valid
+		id, _ := binary.ReadUvarint(r)
+		state = append(state, int(id))
+	}
+	return state
+}
@@ -0,0 +1,63 @@
+package markov
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestModel_JSON(t *testing.T) {
+	t.Parallel()
+
+	m := trainLetters(t, 2, testNames...)
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal("Marshal() error:", err)
+	}
+	if again, _ := json.Marshal(m); string(again) != string(data) {
+		t.Error("Marshal() is not deterministic")
+	}
+
+	var decoded Model
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("Unmarshal() error:", err)
+	}
+	if decoded.Order() != m.Order() {
+		t.Errorf("decoded Order() = %d, want %d", decoded.Order(), m.Order())
+	}
+
+	// The same seed generates the same sequences from both Models
+	a := m.WithSource(rand.NewPCG(3, 4))
+	b := decoded.WithSource(rand.NewPCG(3, 4))
+	for range 50 {
+		if got, want := b.Generate(20), a.Generate(20); !slices.Equal(got, want) {
+			t.Fatalf("decoded Generate() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestModel_UnmarshalJSONInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "order", data: `{"order": 0, "tokens": [], "transitions": []}`},
+		{name: "state length", data: `{"order": 2, "tokens": ["a"], "transitions": [{"state": [0], "next": [{"token": 1, "count": 1}]}]}`},
+		{name: "unknown state token", data: `{"order": 1, "tokens": ["a"], "transitions": [{"state": [2], "next": [{"token": 1, "count": 1}]}]}`},
+		{name: "unknown next token", data: `{"order": 1, "tokens": ["a"], "transitions": [{"state": [0], "next": [{"token": 5, "count": 1}]}]}`},
+		{name: "no counts", data: `{"order": 1, "tokens": ["a"], "transitions": [{"state": [0], "next": [{"token": 1, "count": 0}]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var m Model
+			if err := json.Unmarshal([]byte(tt.data), &m); !errors.Is(err, ErrInvalidModel) {
+				t.Errorf("Unmarshal() error = %v, wantErr %v", err, ErrInvalidModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package markov generates token sequences, such as names from letters or
+// dialogue from words, with order-n Markov chains whose states each select the
+// next token with a weightedoption.Selector.
+package markov
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// boundary is the token id which marks both the start and the end of a
+// sequence. It is never the id of a trained token.
+const boundary = 0
+
+// ErrNotTrained is returned by Trainer.Model when no sequences have been trained.
+var ErrNotTrained = errors.New("no sequences trained")
+
+// Trainer counts the transitions between tokens in sequences to build a Model.
+// It is not safe for concurrent use.
+type Trainer struct {
+	order  int
+	tokens []string
+	ids    map[string]int
+	counts map[string]map[int]uint
+}
+
+// NewTrainer creates a new Trainer for a chain whose states are the previous
+// order tokens. If order is less than 1 it is 1.
+func NewTrainer(order int) *Trainer {
+	return &Trainer{
+		order: max(order, 1),
+		// The boundary takes id 0
+		tokens: []string{""},
+		ids:    make(map[string]int),
+		counts: make(map[string]map[int]uint),
+	}
+}
+
+// Train counts every transition in the sequence of tokens, including from its
+// start to the first token and from the last token to its end.
+func (t *Trainer) Train(tokens ...string) {
+	state := make([]int, t.order)
+	for _, token := range tokens {
+		id, ok := t.ids[token]
+		if !ok {
+			id = len(t.tokens)
+			t.tokens = append(t.tokens, token)
+			t.ids[token] = id
+		}
+		t.count(state, id)
+		state = append(state[1:], id)
+	}
+	t.count(state, boundary)
+}
+
+// count records a transition from state to the token with id next.
+func (t *Trainer) count(state []int, next int) {
+	key := stateKey(state)
+	if t.counts[key] == nil {
+		t.counts[key] = make(map[int]uint)
+	}
+	t.counts[key][next]++
+}
+
+// Model builds a Model from the transitions counted so far, with a Selector
+// for each state weighted by how often each token followed it. If no
+// sequences have been trained ErrNotTrained is returned.
+func (t *Trainer) Model() (*Model, error) {
+	if len(t.counts) == 0 {
+		return nil, ErrNotTrained
+	}
+
+	transitions := make(map[string][]weightedoption.Option[int, uint], len(t.counts))
+	for key, next := range t.counts {
+		opts := make([]weightedoption.Option[int, uint], 0, len(next))
+		for id, count := range next {
+			opts = append(opts, weightedoption.NewOption(id, count))
+		}
+		// Map order is random, so sort to make generation reproducible
+		slices.SortFunc(opts, func(a, b weightedoption.Option[int, uint]) int {
+			return a.Data - b.Data
+		})
+		transitions[key] = opts
+	}
+	return newModel(t.order, slices.Clone(t.tokens), transitions)
+}
+
+// Model is a trained Markov chain. It is safe for concurrent use, unless it
+// was returned by WithSource.
+type Model struct {
+	order       int
+	tokens      []string
+	ids         map[string]int
+	transitions map[string][]weightedoption.Option[int, uint]
+	selectors   map[string]*weightedoption.Selector[int, uint]
+	source      rand.Source
+}
+
+// newModel creates a Model with a Selector for each state's transitions.
+func newModel(order int, tokens []string, transitions map[string][]weightedoption.Option[int, uint]) (*Model, error) {
+	selectors := make(map[string]*weightedoption.Selector[int, uint], len(transitions))
+	for key, opts := range transitions {
+		s, err := weightedoption.NewSelector(opts...)
+		if err != nil {
+			return nil, err
+		}
+		selectors[key] = s
+	}
+	ids := make(map[string]int, len(tokens)-1)
+	for id, token := range tokens[1:] {
+		ids[token] = id + 1
+	}
+	return &Model{order: order, tokens: tokens, ids: ids, transitions: transitions, selectors: selectors}, nil
+}
+
+// Order returns the number of previous tokens each state is made of.
+func (m *Model) Order() int {
+	return m.order
+}
+
+// WithSource returns a copy of the Model which draws from src instead of the
+// global random number generator, so a seeded src makes generation
+// reproducible. The copy shares its states with m. As src is usually not safe
+// for concurrent use, neither is the returned Model.
+func (m *Model) WithSource(src rand.Source) *Model {
+	c := *m
+	c.source = src
+	return &c
+}
+
+// Generate returns a sequence of at most maxLen tokens from the start of a
+// sequence until its end.
+func (m *Model) Generate(maxLen int) []string {
+	return slices.Collect(limit(m.Tokens(nil), maxLen))
+}
+
+// GenerateFrom returns a sequence of at most maxLen tokens which continues
+// prefix until the end of a sequence. Only the last tokens of prefix, up to
+// the Model's order, are used. If that state was never seen in training the
+// sequence is empty.
+func (m *Model) GenerateFrom(prefix []string, maxLen int) []string {
+	return slices.Collect(limit(m.Tokens(prefix), maxLen))
+}
+
+// Tokens returns a sequence of tokens which continues prefix, or starts a new
+// sequence if prefix is empty, until the end of a sequence. Chains which can
+// loop forever should be limited by the caller.
+func (m *Model) Tokens(prefix []string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		state, ok := m.state(prefix)
+		if !ok {
+			return
+		}
+
+		for {
+			s, ok := m.selectors[stateKey(state)]
+			if !ok {
+				return
+			}
+			if m.source != nil {
+				s = s.WithSource(m.source)
+			}
+
+			id := s.Select()
+			if id == boundary || !yield(m.tokens[id]) {
+				return
+			}
+			state = append(state[1:], id)
+		}
+	}
+}
+
+// state returns the state at the end of prefix, padded with the start of a
+// sequence when it is shorter than the order, and false if it contains a token
+// which was never trained.
+func (m *Model) state(prefix []string) ([]int, bool) {
+	state := make([]int, m.order)
+	if len(prefix) > m.order {
+		prefix = prefix[len(prefix)-m.order:]
+	}
+
+	offset := m.order - len(prefix)
+	for i, token := range prefix {
+		id, ok := m.ids[token]
+		if !ok {
+			return nil, false
+		}
+		state[offset+i] = id
+	}
+	return state, true
+}
+
+// limit returns a sequence of at most n values from seq.
+func limit[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n < 1 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// stateKey encodes a state as a map key.
+func stateKey(state []int) string {
+	b := make([]byte, 0, len(state))
+	for _, id := range state {
+		b = binary.AppendUvarint(b, uint64(id))
+	}
+	return string(b)
+}
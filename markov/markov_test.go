@@ -0,0 +1,141 @@
+package markov
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testNames = []string{"anna", "hannah", "ada", "nan", "dana", "hana"}
+
+func trainLetters(t *testing.T, order int, words ...string) *Model {
+	t.Helper()
+	tr := NewTrainer(order)
+	for _, w := range words {
+		tr.Train(strings.Split(w, "")...)
+	}
+	m, err := tr.Model()
+	if err != nil {
+		t.Fatal("Failed to build Model:", err)
+	}
+	return m
+}
+
+func TestTrainer_Model(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewTrainer(2).Model(); err != ErrNotTrained {
+		t.Errorf("Model() error = %v, wantErr %v", err, ErrNotTrained)
+	}
+	if got := NewTrainer(0).order; got != 1 {
+		t.Errorf("NewTrainer(0) order = %d, want 1", got)
+	}
+
+	m := trainLetters(t, 2, "ab")
+	if got := m.Order(); got != 2 {
+		t.Errorf("Order() = %d, want 2", got)
+	}
+	// start start -> a, start a -> b, a b -> end
+	if got := len(m.selectors); got != 3 {
+		t.Errorf("Model has %d states, want 3", got)
+	}
+}
+
+func TestModel_Generate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		order  int
+		words  []string
+		maxLen int
+		want   []string
+	}{
+		{name: "single sequence", order: 1, words: []string{"abc"}, maxLen: 10, want: []string{"a", "b", "c"}},
+		{name: "limited", order: 1, words: []string{"abc"}, maxLen: 2, want: []string{"a", "b"}},
+		{name: "zero length", order: 1, words: []string{"abc"}, maxLen: 0, want: nil},
+		{name: "higher order", order: 3, words: []string{"abcd"}, maxLen: 10, want: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := trainLetters(t, tt.order, tt.words...)
+			if got := m.Generate(tt.maxLen); !slices.Equal(got, tt.want) {
+				t.Errorf("Generate(%d) = %v, want %v", tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateTransitions(t *testing.T) {
+	t.Parallel()
+
+	// Pad with ^ for the start and $ for the end of each name
+	trigrams := func(word string) []string {
+		padded := "^^" + word + "$"
+		grams := make([]string, 0, len(padded)-2)
+		for i := range len(padded) - 2 {
+			grams = append(grams, padded[i:i+3])
+		}
+		return grams
+	}
+	trained := make(map[string]bool)
+	for _, name := range testNames {
+		for _, gram := range trigrams(name) {
+			trained[gram] = true
+		}
+	}
+
+	m := trainLetters(t, 2, testNames...)
+	for range 1000 {
+		word := strings.Join(m.Generate(100), "")
+		for _, gram := range trigrams(word) {
+			if !trained[gram] {
+				t.Fatalf("Generate() = %q, which contains %q which was never trained", word, gram)
+			}
+		}
+	}
+}
+
+func TestModel_GenerateFrom(t *testing.T) {
+	t.Parallel()
+
+	m := trainLetters(t, 2, "hello")
+	tests := []struct {
+		name   string
+		prefix []string
+		want   []string
+	}{
+		{name: "empty", prefix: nil, want: []string{"h", "e", "l", "l", "o"}},
+		{name: "start", prefix: []string{"h"}, want: []string{"e", "l", "l", "o"}},
+		{name: "longer than order", prefix: []string{"h", "e", "l"}, want: []string{"l", "o"}},
+		{name: "unseen state", prefix: []string{"l"}, want: nil},
+		{name: "unknown token", prefix: []string{"z"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := m.GenerateFrom(tt.prefix, 10); !slices.Equal(got, tt.want) {
+				t.Errorf("GenerateFrom(%v) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_WithSource(t *testing.T) {
+	t.Parallel()
+
+	m := trainLetters(t, 1, testNames...)
+	generate := func() []string {
+		r := m.WithSource(rand.NewPCG(1, 2))
+		var words []string
+		for range 20 {
+			words = append(words, strings.Join(r.Generate(20), ""))
+		}
+		return words
+	}
+	if a, b := generate(), generate(); !slices.Equal(a, b) {
+		t.Errorf("WithSource() with the same seed generated %v then %v", a, b)
+	}
+}
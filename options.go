@@ -0,0 +1,112 @@
+package weightedoption
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+)
+
+// ErrUnsupportedOption is returned when a SelectorOption is passed to a
+// constructor which it doesn't apply to.
+var ErrUnsupportedOption = errors.New("SelectorOption does not apply")
+
+// Algorithm is the algorithm a Selector uses to select Options.
+type Algorithm int
+
+const (
+	// AlgorithmBinarySearch selects Options with a binary search over the
+	// running total weights, taking O(log n) time per selection. It is the
+	// default.
+	AlgorithmBinarySearch Algorithm = iota
+	// AlgorithmAlias selects Options with Vose's alias method, taking O(1) time
+	// per selection after O(n) setup. The number of Options multiplied by the
+	// total weight must not exceed the max uint value for this system's
+	// architecture.
+	AlgorithmAlias
+)
+
+// SelectorOption configures a Selector created by NewSelectorWith.
+type SelectorOption func(*selectorConfig)
+
+type selectorConfig struct {
+	source          rand.Source
+	strict          bool
+	precision       int
+	mergeDuplicates bool
+	algorithm       Algorithm
+}
+
+func newSelectorConfig(cfg []SelectorOption) selectorConfig {
+	c := selectorConfig{precision: -1}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+	return c
+}
+
+// WithSource makes the Selector draw from src instead of the global random
+// number generator. As src is usually not safe for concurrent use, neither is
+// the Selector.
+func WithSource(src rand.Source) SelectorOption {
+	return func(c *selectorConfig) {
+		c.source = src
+	}
+}
+
+// WithStrict makes NewSelectorWith return a *ValidationError from Validate
+// instead of silently ignoring Options which can never be selected.
+func WithStrict() SelectorOption {
+	return func(c *selectorConfig) {
+		c.strict = true
+	}
+}
+
+// WithPrecision scales float weights by 10^digits, rounding to the nearest
+// integer, instead of by enough to keep every fractional digit. Weights which
+// round down to 0 are ignored. A negative digits restores the default.
+func WithPrecision(digits int) SelectorOption {
+	return func(c *selectorConfig) {
+		c.precision = digits
+	}
+}
+
+// WithMergeDuplicates combines Options with equal Data into a single Option,
+// at the position of the first, whose weight is the sum of their weights. Data
+// which isn't comparable is never merged.
+func WithMergeDuplicates() SelectorOption {
+	return func(c *selectorConfig) {
+		c.mergeDuplicates = true
+	}
+}
+
+// WithAlgorithm sets the Algorithm the Selector uses to select Options.
+func WithAlgorithm(a Algorithm) SelectorOption {
+	return func(c *selectorConfig) {
+		c.algorithm = a
+	}
+}
+
+// mergeDuplicates combines the weights of equal comparable options into the
+// first of them.
+func mergeDuplicates[DataType any](options []DataType, indices []int, weights []uint) ([]DataType, []int, []uint, error) {
+	first := make(map[any]int, len(options))
+	n := 0
+	for i, data := range options {
+		if isComparable(data) {
+			if j, ok := first[data]; ok {
+				// The merged weight is part of the total, so overflowing it overflows the total
+				if (math.MaxInt - weights[j]) < weights[i] {
+					return nil, nil, nil, ErrTotalWeightOverflow
+				}
+				weights[j] += weights[i]
+				continue
+			}
+			first[data] = n
+		}
+		options[n] = data
+		indices[n] = indices[i]
+		weights[n] = weights[i]
+		n++
+	}
+	return options[:n], indices[:n], weights[:n], nil
+}
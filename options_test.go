@@ -0,0 +1,150 @@
+package weightedoption
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestNewSelectorWith(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cs          []Option[rune, float64]
+		cfg         []SelectorOption
+		wantOptions []rune
+		wantWeights []uint
+		wantErr     error
+	}{
+		{
+			name:        "no selector options",
+			cs:          []Option[rune, float64]{{Data: 'a', Weight: 0.25}, {Data: 'b', Weight: 1}},
+			wantOptions: []rune{'a', 'b'},
+			wantWeights: []uint{25, 100},
+		},
+		{
+			name:    "strict",
+			cs:      []Option[rune, float64]{{Data: 'a', Weight: -1}, {Data: 'b', Weight: 1}},
+			cfg:     []SelectorOption{WithStrict()},
+			wantErr: &ValidationError{},
+		},
+		{
+			name:        "precision",
+			cs:          []Option[rune, float64]{{Data: 'a', Weight: 0.126}, {Data: 'b', Weight: 1}},
+			cfg:         []SelectorOption{WithPrecision(2)},
+			wantOptions: []rune{'a', 'b'},
+			wantWeights: []uint{13, 100},
+		},
+		{
+			name:        "precision rounds weight down to 0",
+			cs:          []Option[rune, float64]{{Data: 'a', Weight: 0.001}, {Data: 'b', Weight: 1}},
+			cfg:         []SelectorOption{WithPrecision(2)},
+			wantOptions: []rune{'b'},
+			wantWeights: []uint{100},
+		},
+		{
+			name:    "precision rounds every weight down to 0",
+			cs:      []Option[rune, float64]{{Data: 'a', Weight: 0.001}},
+			cfg:     []SelectorOption{WithPrecision(2)},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name:    "strict precision rounds every weight down to 0",
+			cs:      []Option[rune, float64]{{Data: 'a', Weight: 0.001}},
+			cfg:     []SelectorOption{WithPrecision(2), WithStrict()},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name: "merge duplicates",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1},
+				{Data: 'b', Weight: 2},
+				{Data: 'a', Weight: 3},
+			},
+			cfg:         []SelectorOption{WithMergeDuplicates()},
+			wantOptions: []rune{'a', 'b'},
+			wantWeights: []uint{4, 2},
+		},
+		{
+			name: "strict merge duplicates",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1},
+				{Data: 'a', Weight: 3},
+			},
+			cfg:         []SelectorOption{WithStrict(), WithMergeDuplicates()},
+			wantOptions: []rune{'a'},
+			wantWeights: []uint{4},
+		},
+		{
+			name: "merge duplicates overflow",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 6e18},
+				{Data: 'a', Weight: 6e18},
+			},
+			cfg:     []SelectorOption{WithMergeDuplicates()},
+			wantErr: ErrTotalWeightOverflow,
+		},
+		{
+			name:        "alias",
+			cs:          []Option[rune, float64]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 3}},
+			cfg:         []SelectorOption{WithAlgorithm(AlgorithmAlias)},
+			wantOptions: []rune{'a', 'b'},
+			wantWeights: []uint{1, 3},
+		},
+		{
+			name:    "alias overflow",
+			cs:      []Option[rune, float64]{{Data: 'a', Weight: math.MaxInt / 2}, {Data: 'b', Weight: math.MaxInt / 2}, {Data: 'c', Weight: 1}},
+			cfg:     []SelectorOption{WithAlgorithm(AlgorithmAlias)},
+			wantErr: ErrTotalWeightOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := NewSelectorWith(tt.cs, tt.cfg...)
+			if tt.wantErr != nil {
+				var verr *ValidationError
+				if errors.As(tt.wantErr, &verr) {
+					if !errors.As(err, &verr) {
+						t.Errorf("NewSelectorWith() error = %v, want *ValidationError", err)
+					}
+				} else if !errors.Is(err, tt.wantErr) {
+					t.Errorf("NewSelectorWith() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSelectorWith() error = %v", err)
+			}
+			if !slices.Equal(s.options, tt.wantOptions) {
+				t.Errorf("NewSelectorWith() options = %c, want %c", s.options, tt.wantOptions)
+			}
+			if got := weightsOf(s); !slices.Equal(got, tt.wantWeights) {
+				t.Errorf("NewSelectorWith() weights = %v, want %v", got, tt.wantWeights)
+			}
+		})
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	for _, algorithm := range []Algorithm{AlgorithmBinarySearch, AlgorithmAlias} {
+		a, err := NewSelectorWith(options, WithSource(rand.NewPCG(1, 2)), WithAlgorithm(algorithm))
+		if err != nil {
+			t.Fatal("Failed to create Selector:", err)
+		}
+		b, err := NewSelectorWith(options, WithSource(rand.NewPCG(1, 2)), WithAlgorithm(algorithm))
+		if err != nil {
+			t.Fatal("Failed to create Selector:", err)
+		}
+
+		if !slices.Equal(a.SelectN(100, nil), b.SelectN(100, nil)) {
+			t.Errorf("Selectors with algorithm %d and equally seeded sources returned different draws", algorithm)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package weightedoption
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+)
+
+// prdMaxIterations bounds the bisection in PRDConstant, which halves the
+// search interval each time and so can't improve on float64 precision after
+// this many iterations.
+const prdMaxIterations = 128
+
+// prdTailEpsilon is the chance of failing every attempt so far below which
+// prdProbability stops summing, as the remaining attempts no longer change
+// the float64 result.
+const prdTailEpsilon = 1e-20
+
+// prdAsymptoticC is the constant below which prdProbability uses the
+// asymptotic expansion of the expected number of attempts instead of summing
+// it, which is then accurate to float64 precision. Summing takes about
+// sqrt(1/c) terms, so it stays fast above this.
+const prdAsymptoticC = 1e-6
+
+// PRDConstant returns the constant C of the pseudo-random distribution whose
+// long-run success rate is p, to float64 precision. The chance of success on
+// the Nth attempt after a success is min(1, N*C). p is clamped to [0, 1]. It
+// takes at most a few milliseconds for any p.
+func PRDConstant(p float64) float64 {
+	if p <= 0 || math.IsNaN(p) {
+		return 0
+	}
+	if p >= 1 {
+		return 1
+	}
+
+	lower, upper := 0.0, p
+	for range prdMaxIterations {
+		mid := (lower + upper) / 2
+		if mid == lower || mid == upper {
+			break
+		}
+		if prdProbability(mid) > p {
+			upper = mid
+		} else {
+			lower = mid
+		}
+	}
+	return (lower + upper) / 2
+}
+
+// prdProbability returns the long-run success rate of the pseudo-random
+// distribution with constant c, which is 1 over the expected number of
+// attempts per success. The expected number of attempts is the sum over n of
+// the chance of failing the first n attempts, which falls like exp(-c*n²/2),
+// so only about sqrt(1/c) terms are needed before it is negligible. For
+// smaller c that is too many, and the sum is Ramanujan's Q function of 1/c,
+// whose asymptotic expansion is used instead.
+func prdProbability(c float64) float64 {
+	if c < prdAsymptoticC {
+		return 1 / prdAsymptoticAttempts(c)
+	}
+	return 1 / prdSummedAttempts(c)
+}
+
+// prdSummedAttempts returns the expected number of attempts per success for
+// the constant c by summing the chance of failing the first n attempts.
+func prdSummedAttempts(c float64) float64 {
+	expectedAttempts, failedAll := 0.0, 1.0
+	for n := 1; failedAll >= prdTailEpsilon; n++ {
+		expectedAttempts += failedAll
+		failedAll *= 1 - math.Min(1, float64(n)*c)
+	}
+	return expectedAttempts
+}
+
+// prdAsymptoticAttempts returns the expected number of attempts per success
+// for a small constant c, from the first terms of the asymptotic expansion of
+// Ramanujan's Q function. The terms left out are of the order of c².
+func prdAsymptoticAttempts(c float64) float64 {
+	return math.Sqrt(math.Pi/(2*c)) - 1.0/3 + math.Sqrt(math.Pi*c/2)/12 - 4*c/135 +
+		c*math.Sqrt(math.Pi*c/2)/288
+}
+
+// PRD rolls boolean proc chances with a pseudo-random distribution: the chance
+// of success starts at C, grows by C after each failure and resets after a
+// success. The long-run success rate equals the nominal probability, but long
+// streaks of successes or failures are rarer than with independent rolls.
+// State is tracked separately per Key, such as a player or unit ID. It is safe
+// for concurrent use.
+type PRD[Key comparable] struct {
+	mu          sync.Mutex
+	rng         *rand.Rand
+	probability float64
+	c           float64
+	failures    map[Key]int
+}
+
+// NewPRD creates a new PRD whose nominal probability of success is the weight
+// of the Options with true Data over the total weight, as it would be when
+// selecting from NewSelector(opts...). The same rules as NewSelector apply to
+// the Options.
+func NewPRD[Key comparable, WeightType WeightConstraint](
+	opts ...Option[bool, WeightType],
+) (*PRD[Key], error) {
+	return NewPRDWith[Key](opts)
+}
+
+// NewPRDWith creates a new PRD like NewPRD, with its nominal probability
+// computed as it would be when selecting from NewSelectorWith(opts, cfg...).
+// A source set by WithSource is used for every Roll, which makes the rolls
+// reproducible.
+func NewPRDWith[Key comparable, WeightType WeightConstraint](
+	opts []Option[bool, WeightType],
+	cfg ...SelectorOption,
+) (*PRD[Key], error) {
+	s, err := NewSelectorWith(opts, cfg...)
+	if err != nil {
+		return nil, err
+	}
+
+	var success uint
+	for i, data := range s.options {
+		if data {
+			success += s.weight(i)
+		}
+	}
+	p := float64(success) / float64(s.totalWeight)
+
+	return &PRD[Key]{
+		rng:         s.rng,
+		probability: p,
+		c:           PRDConstant(p),
+		failures:    make(map[Key]int),
+	}, nil
+}
+
+// Probability returns the nominal probability of success.
+func (p *PRD[Key]) Probability() float64 {
+	return p.probability
+}
+
+// C returns the constant by which the chance of success grows after each failure.
+func (p *PRD[Key]) C() float64 {
+	return p.c
+}
+
+// Chance returns the chance of success of the next Roll for key.
+func (p *PRD[Key]) Chance(key Key) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.chance(key)
+}
+
+// chance must be called while holding p.mu.
+func (p *PRD[Key]) chance(key Key) float64 {
+	return math.Min(1, float64(p.failures[key]+1)*p.c)
+}
+
+// Roll returns whether the next attempt for key succeeds, resetting its chance
+// after a success and growing it after a failure.
+func (p *PRD[Key]) Roll(key Key) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r := rand.Float64
+	if p.rng != nil {
+		r = p.rng.Float64
+	}
+	if r() < p.chance(key) {
+		delete(p.failures, key)
+		return true
+	}
+	p.failures[key]++
+	return false
+}
+
+// Reset forgets the failures of key, resetting its chance of success to C.
+func (p *PRD[Key]) Reset(key Key) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.failures, key)
+}
@@ -0,0 +1,176 @@
+package weightedoption
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestPRDConstant(t *testing.T) {
+	t.Parallel()
+
+	// Published values of C for common nominal probabilities
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{p: 0, want: 0},
+		{p: 0.05, want: 0.003801658303553},
+		{p: 0.25, want: 0.084744091852316},
+		{p: 0.5, want: 0.302103025348741},
+		{p: 1, want: 1},
+	}
+	for _, tt := range tests {
+		got := PRDConstant(tt.p)
+		if math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("PRDConstant(%v) = %.15f, want %.15f", tt.p, got, tt.want)
+		}
+		if tt.p > 0 && tt.p < 1 {
+			if back := prdProbability(got); math.Abs(back-tt.p) > 1e-12 {
+				t.Errorf("prdProbability(PRDConstant(%v)) = %.15f", tt.p, back)
+			}
+		}
+	}
+}
+
+func TestPRDConstantSmallProbability(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []float64{1e-3, 3e-4, 1e-5, 1e-7, 1e-9} {
+		start := time.Now()
+		c := PRDConstant(p)
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("PRDConstant(%v) took %v", p, elapsed)
+		}
+		// C approaches pi*p^2/2 as p approaches 0
+		if approx := math.Pi * p * p / 2; math.Abs(c-approx)/approx > 0.01 {
+			t.Errorf("PRDConstant(%v) = %v, want about %v", p, c, approx)
+		}
+		if back := prdProbability(c); math.Abs(back-p)/p > 1e-9 {
+			t.Errorf("prdProbability(PRDConstant(%v)) = %v", p, back)
+		}
+	}
+
+	// The expansion agrees with the sum where prdProbability switches between them
+	summed := prdSummedAttempts(prdAsymptoticC)
+	if expanded := prdAsymptoticAttempts(prdAsymptoticC); math.Abs(expanded-summed)/summed > 1e-13 {
+		t.Errorf("prdAsymptoticAttempts() = %v, want about %v", expanded, summed)
+	}
+
+	prd, err := NewPRD[int](NewOption(true, 1), NewOption(false, 1_000_000_000))
+	if err != nil {
+		t.Fatal("Failed to create PRD:", err)
+	}
+	if prd.C() <= 0 {
+		t.Errorf("C() = %v, want a positive constant", prd.C())
+	}
+}
+
+func TestNewPRD(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cs      []Option[bool, float64]
+		wantP   float64
+		wantErr error
+	}{
+		{
+			name:    "no options",
+			cs:      []Option[bool, float64]{},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name:  "quarter",
+			cs:    []Option[bool, float64]{{Data: true, Weight: 2.5}, {Data: false, Weight: 7.5}},
+			wantP: 0.25,
+		},
+		{
+			name:  "never",
+			cs:    []Option[bool, float64]{{Data: false, Weight: 1}},
+			wantP: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			prd, err := NewPRD[string](tt.cs...)
+			if err != tt.wantErr {
+				t.Fatalf("NewPRD() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && prd.Probability() != tt.wantP {
+				t.Errorf("Probability() = %v, want %v", prd.Probability(), tt.wantP)
+			}
+		})
+	}
+}
+
+func TestPRD_Roll(t *testing.T) {
+	t.Parallel()
+
+	prd, err := NewPRD[int](NewOption(true, 25), NewOption(false, 75))
+	if err != nil {
+		t.Fatal("Failed to create PRD:", err)
+	}
+
+	// After enough failures the chance reaches 1, capping every streak of failures
+	maxFailures := int(math.Ceil(1/prd.C())) - 1
+	successes, failures, longest := 0, 0, 0
+	for i := 0; i < testIterations; i++ {
+		if prd.Roll(1) {
+			successes++
+			failures = 0
+			continue
+		}
+		failures++
+		longest = max(longest, failures)
+	}
+
+	if rate := float64(successes) / float64(testIterations); math.Abs(rate-0.25) > 0.005 {
+		t.Errorf("success rate = %v, want about 0.25", rate)
+	}
+	if longest > maxFailures {
+		t.Errorf("longest failure streak = %d, want at most %d", longest, maxFailures)
+	}
+
+	if got := prd.Chance(2); got != prd.C() {
+		t.Errorf("Chance() for a new key = %v, want %v", got, prd.C())
+	}
+	for prd.Roll(2) {
+		// Roll until the first failure
+	}
+	if got := prd.Chance(2); got != 2*prd.C() {
+		t.Errorf("Chance() after a failure = %v, want %v", got, 2*prd.C())
+	}
+	prd.Reset(2)
+	if got := prd.Chance(2); got != prd.C() {
+		t.Errorf("Chance() after Reset() = %v, want %v", got, prd.C())
+	}
+}
+
+func TestNewPRDWith(t *testing.T) {
+	t.Parallel()
+
+	rolls := func() []bool {
+		prd, err := NewPRDWith[int](
+			[]Option[bool, int]{NewOption(true, 1), NewOption(false, 3)},
+			WithSource(rand.NewPCG(11, 12)),
+		)
+		if err != nil {
+			t.Fatal("Failed to create PRD:", err)
+		}
+		got := make([]bool, 100)
+		for i := range got {
+			got[i] = prd.Roll(i % 3)
+		}
+		return got
+	}
+
+	a, b := rolls(), rolls()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("rolls with the same source differ at %d", i)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package weightedoption
+
+import (
+	"math/big"
+	"sync"
+)
+
+// RateUp is a banner where a share of rare drops are replaced by featured
+// Options, such as the 50/50 rule: a rare drop is featured half of the time,
+// and losing the 50/50 guarantees the next rare drop is featured. Guarantee
+// state is tracked separately per Key, such as a user ID. It is safe for
+// concurrent use as long as its Selectors are.
+type RateUp[DataType comparable, WeightType WeightConstraint, Key comparable] struct {
+	mu         sync.Mutex
+	pool       *Selector[DataType, WeightType]
+	isRare     func(DataType) bool
+	featured   *Selector[DataType, WeightType]
+	split      *Selector[bool, WeightType]
+	guaranteed map[Key]bool
+}
+
+// NewRateUp creates a new RateUp which draws from pool, where isRare reports
+// whether a drop is rare. The rare Options of pool should be the standard, off
+// banner, rare drops. When a rare drop isn't guaranteed to be featured, split
+// decides whether it is: the weight of the Options with true Data over the
+// total weight is the chance of winning, e.g. NewOption(true, 50) and
+// NewOption(false, 50). A featured drop is selected from featured by its
+// sub-weights. The same rules as NewSelector apply to split.
+func NewRateUp[DataType comparable, WeightType WeightConstraint, Key comparable](
+	pool *Selector[DataType, WeightType],
+	isRare func(DataType) bool,
+	featured *Selector[DataType, WeightType],
+	split ...Option[bool, WeightType],
+) (*RateUp[DataType, WeightType, Key], error) {
+	s, err := NewSelector(split...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateUp[DataType, WeightType, Key]{
+		pool:       pool,
+		isRare:     isRare,
+		featured:   featured,
+		split:      s,
+		guaranteed: make(map[Key]bool),
+	}, nil
+}
+
+// Pull returns a single DataType for key, updating its guarantee state.
+func (r *RateUp[DataType, WeightType, Key]) Pull(key Key) DataType {
+	drop := r.pool.Select()
+	if !r.isRare(drop) {
+		return drop
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.guaranteed[key] {
+		delete(r.guaranteed, key)
+		return r.featured.Select()
+	}
+	if r.split.Select() {
+		return r.featured.Select()
+	}
+
+	r.guaranteed[key] = true
+	return drop
+}
+
+// Guaranteed reports whether the next rare drop for key is guaranteed to be
+// featured.
+func (r *RateUp[DataType, WeightType, Key]) Guaranteed(key Key) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.guaranteed[key]
+}
+
+// Reset forgets the guarantee state of key.
+func (r *RateUp[DataType, WeightType, Key]) Reset(key Key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.guaranteed, key)
+}
+
+// Rates returns the exact probability of each DataType on the next Pull for
+// key.
+func (r *RateUp[DataType, WeightType, Key]) Rates(key Key) map[DataType]*big.Rat {
+	return r.rates(r.Guaranteed(key))
+}
+
+// ConsolidatedRates returns the exact long-run probability of each DataType
+// over many Pulls, accounting for how often the guarantee is active. With a
+// chance s of winning the split, a pull is guaranteed (1-s)/(2-s) of the time.
+func (r *RateUp[DataType, WeightType, Key]) ConsolidatedRates() map[DataType]*big.Rat {
+	lose := r.loseChance()
+	denom := new(big.Rat).Add(big.NewRat(1, 1), lose)
+	guaranteedShare := new(big.Rat).Quo(lose, denom)
+	normalShare := new(big.Rat).Quo(big.NewRat(1, 1), denom)
+
+	rates := r.rates(false)
+	for _, p := range rates {
+		p.Mul(p, normalShare)
+	}
+	for data, p := range r.rates(true) {
+		p.Mul(p, guaranteedShare)
+		addRate(rates, data, p)
+	}
+	return rates
+}
+
+// loseChance returns the exact chance of losing the split.
+func (r *RateUp[DataType, WeightType, Key]) loseChance() *big.Rat {
+	var lose uint
+	for i, win := range r.split.options {
+		if !win {
+			lose += r.split.weight(i)
+		}
+	}
+	return ratio(lose, r.split.totalWeight)
+}
+
+// rates returns the exact probability of each DataType on a Pull with or
+// without the guarantee.
+func (r *RateUp[DataType, WeightType, Key]) rates(guaranteed bool) map[DataType]*big.Rat {
+	rates := make(map[DataType]*big.Rat)
+	lose := r.loseChance()
+
+	rare := new(big.Rat)
+	for i, data := range r.pool.options {
+		p := ratio(r.pool.weight(i), r.pool.totalWeight)
+		if !r.isRare(data) {
+			addRate(rates, data, p)
+			continue
+		}
+		rare.Add(rare, p)
+		if !guaranteed {
+			addRate(rates, data, p.Mul(p, lose))
+		}
+	}
+
+	featuredShare := rare
+	if !guaranteed {
+		featuredShare.Mul(rare, new(big.Rat).Sub(big.NewRat(1, 1), lose))
+	}
+	for i, data := range r.featured.options {
+		p := ratio(r.featured.weight(i), r.featured.totalWeight)
+		addRate(rates, data, p.Mul(p, featuredShare))
+	}
+
+	return rates
+}
+
+// addRate adds p to the probability of data in rates.
+func addRate[DataType comparable](rates map[DataType]*big.Rat, data DataType, p *big.Rat) {
+	if sum, ok := rates[data]; ok {
+		sum.Add(sum, p)
+		return
+	}
+	rates[data] = new(big.Rat).Set(p)
+}
+
+// ratio returns a/b as an exact rational number.
+func ratio(a, b uint) *big.Rat {
+	return new(big.Rat).SetFrac(new(big.Int).SetUint64(uint64(a)), new(big.Int).SetUint64(uint64(b)))
+}
@@ -0,0 +1,140 @@
+package weightedoption
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func newTestRateUp(t *testing.T) *RateUp[string, int, string] {
+	t.Helper()
+
+	pool := mustSelector(t,
+		NewOption("5★ Standard A", 3),
+		NewOption("5★ Standard B", 3),
+		NewOption("4★", 94),
+	)
+	featured := mustSelector(t,
+		NewOption("5★ Featured A", 3),
+		NewOption("5★ Featured B", 1),
+	)
+	r, err := NewRateUp[string, int, string](
+		pool,
+		func(data string) bool { return strings.HasPrefix(data, "5★") },
+		featured,
+		NewOption(true, 50), NewOption(false, 50),
+	)
+	if err != nil {
+		t.Fatal("Failed to create RateUp:", err)
+	}
+	return r
+}
+
+func TestNewRateUp(t *testing.T) {
+	t.Parallel()
+
+	pool := mustSelector(t, NewOption("a", 1))
+	_, err := NewRateUp[string, int, string](pool, func(string) bool { return true }, pool)
+	if err != ErrNoValidOptions {
+		t.Errorf("NewRateUp() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+}
+
+func TestRateUp_Rates(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRateUp(t)
+
+	tests := []struct {
+		name  string
+		rates map[string]*big.Rat
+		want  map[string]*big.Rat
+	}{
+		{
+			name:  "without guarantee",
+			rates: r.rates(false),
+			want: map[string]*big.Rat{
+				"5★ Standard A": big.NewRat(3, 200),
+				"5★ Standard B": big.NewRat(3, 200),
+				"5★ Featured A": big.NewRat(9, 400),
+				"5★ Featured B": big.NewRat(3, 400),
+				"4★":            big.NewRat(94, 100),
+			},
+		},
+		{
+			name:  "with guarantee",
+			rates: r.rates(true),
+			want: map[string]*big.Rat{
+				"5★ Featured A": big.NewRat(9, 200),
+				"5★ Featured B": big.NewRat(3, 200),
+				"4★":            big.NewRat(94, 100),
+			},
+		},
+		{
+			name:  "consolidated",
+			rates: r.ConsolidatedRates(),
+			want: map[string]*big.Rat{
+				"5★ Standard A": big.NewRat(1, 100),
+				"5★ Standard B": big.NewRat(1, 100),
+				"5★ Featured A": big.NewRat(3, 100),
+				"5★ Featured B": big.NewRat(1, 100),
+				"4★":            big.NewRat(94, 100),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			total := new(big.Rat)
+			for data, p := range tt.rates {
+				total.Add(total, p)
+				want, ok := tt.want[data]
+				if !ok {
+					want = new(big.Rat)
+				}
+				if p.Cmp(want) != 0 {
+					t.Errorf("rate of %q = %v, want %v", data, p, want)
+				}
+			}
+			if total.Cmp(big.NewRat(1, 1)) != 0 {
+				t.Errorf("rates sum to %v, want 1", total)
+			}
+		})
+	}
+}
+
+func TestRateUp_Pull(t *testing.T) {
+	t.Parallel()
+
+	r := newTestRateUp(t)
+
+	counts := make(map[string]int)
+	for i := 0; i < testIterations; i++ {
+		guaranteed := r.Guaranteed("user")
+		drop := r.Pull("user")
+		counts[drop]++
+
+		if guaranteed && strings.HasPrefix(drop, "5★ Standard") {
+			t.Fatalf("Pull() = %q while guaranteed a featured drop", drop)
+		}
+		if strings.HasPrefix(drop, "5★ Standard") != r.Guaranteed("user") && strings.HasPrefix(drop, "5★") {
+			t.Fatalf("Pull() = %q left guarantee %v", drop, r.Guaranteed("user"))
+		}
+	}
+
+	for data, want := range r.ConsolidatedRates() {
+		f, _ := want.Float64()
+		got := float64(counts[data]) / float64(testIterations)
+		if diff := got - f; diff > 0.002 || diff < -0.002 {
+			t.Errorf("rate of %q = %v, want about %v", data, got, f)
+		}
+	}
+
+	r.Reset("user")
+	if r.Guaranteed("user") {
+		t.Error("Guaranteed() after Reset() = true, want false")
+	}
+	if got := r.Rates("user"); got["5★ Standard A"].Cmp(big.NewRat(3, 200)) != 0 {
+		t.Errorf("Rates() after Reset() for a standard drop = %v, want 3/200", got["5★ Standard A"])
+	}
+}
@@ -0,0 +1,144 @@
+package weightedoption
+
+import (
+	"sync"
+)
+
+// Recency configures how an Option of a RecencySelector is held back after it
+// is selected.
+type Recency struct {
+	// Cooldown is the number of selections after an Option is selected during
+	// which it can't be selected again.
+	Cooldown int
+	// Decay is the multiplier applied to an Option's weight once its Cooldown
+	// has passed, e.g. 0.25 makes it a quarter as likely as normal. It must be
+	// in [0, 1]; 0 or 1 disables decay.
+	Decay float64
+	// Recovery is the number of selections, after the Cooldown, over which the
+	// multiplier rises linearly from Decay back to 1.
+	Recovery int
+}
+
+// multiplier returns the weight multiplier of an Option followed by since
+// selections of other Options.
+func (r Recency) multiplier(since int) float64 {
+	if since < r.Cooldown {
+		return 0
+	}
+	if r.Decay <= 0 || r.Decay >= 1 || r.Recovery < 1 {
+		return 1
+	}
+
+	recovered := since - r.Cooldown
+	if recovered >= r.Recovery {
+		return 1
+	}
+	return r.Decay + (1-r.Decay)*float64(recovered)/float64(r.Recovery)
+}
+
+// RecencySelector selects from a Selector while holding back recently selected
+// Options, with a hard Cooldown and/or a Decay of their weight which recovers
+// over subsequent selections, to avoid repeating the same Option back to back.
+// It is safe for concurrent use.
+type RecencySelector[DataType any, WeightType WeightConstraint] struct {
+	mu       sync.Mutex
+	selector *Selector[DataType, WeightType]
+	recency  []Recency
+	// lastSelected is the selection count at which each Option was last
+	// selected, or -1 if it never was.
+	lastSelected []int
+	selections   int
+}
+
+// NewRecencySelector creates a new RecencySelector for s. recency is called
+// once per Option of s, with its index in the Options s was created from and
+// its Data, to get the Option's Recency.
+func NewRecencySelector[DataType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	recency func(i int, data DataType) Recency,
+) *RecencySelector[DataType, WeightType] {
+	rs := &RecencySelector[DataType, WeightType]{
+		selector:     s,
+		recency:      make([]Recency, s.Len()),
+		lastSelected: make([]int, s.Len()),
+	}
+	for i, data := range s.options {
+		rs.recency[i] = recency(s.indices[i], data)
+		rs.lastSelected[i] = -1
+	}
+	return rs
+}
+
+// effectiveWeights returns every Option's weight multiplied by its current
+// Recency multiplier, and their total. It must be called while holding rs.mu.
+func (rs *RecencySelector[DataType, WeightType]) effectiveWeights() ([]float64, float64) {
+	weights := make([]float64, len(rs.recency))
+	var total float64
+	for i, r := range rs.recency {
+		m := 1.0
+		if rs.lastSelected[i] >= 0 {
+			m = r.multiplier(rs.selections - rs.lastSelected[i])
+		}
+		weights[i] = float64(rs.selector.weight(i)) * m
+		total += weights[i]
+	}
+	return weights, total
+}
+
+// Select returns a single DataType selected by the current effective weights
+// and starts its Cooldown. If every Option is on cooldown ErrNoValidOptions is
+// returned, though the selection still counts towards ending the cooldowns.
+func (rs *RecencySelector[DataType, WeightType]) Select() (DataType, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	weights, total := rs.effectiveWeights()
+	rs.selections++
+	if total == 0 {
+		var zero DataType
+		return zero, ErrNoValidOptions
+	}
+
+	r := rs.selector.float64() * total
+	selected := -1
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		selected = i
+		if r < w {
+			break
+		}
+		r -= w
+	}
+
+	rs.lastSelected[selected] = rs.selections
+	return rs.selector.options[selected], nil
+}
+
+// Probabilities returns the current probability of selecting each Option, in
+// the order of the Selector's Options.
+func (rs *RecencySelector[DataType, WeightType]) Probabilities() []float64 {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	weights, total := rs.effectiveWeights()
+	if total == 0 {
+		return weights
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// Reset forgets every previous selection, ending all cooldowns and decay.
+func (rs *RecencySelector[DataType, WeightType]) Reset() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for i := range rs.lastSelected {
+		rs.lastSelected[i] = -1
+	}
+	rs.selections = 0
+}
@@ -0,0 +1,121 @@
+package weightedoption
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRecency_multiplier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		recency Recency
+		since   []int
+		want    []float64
+	}{
+		{name: "no recency", recency: Recency{}, since: []int{0, 1}, want: []float64{1, 1}},
+		{name: "cooldown", recency: Recency{Cooldown: 2}, since: []int{0, 1, 2, 3}, want: []float64{0, 0, 1, 1}},
+		{
+			name:    "decay",
+			recency: Recency{Decay: 0.2, Recovery: 4},
+			since:   []int{0, 1, 2, 4, 10},
+			want:    []float64{0.2, 0.4, 0.6, 1, 1},
+		},
+		{
+			name:    "cooldown then decay",
+			recency: Recency{Cooldown: 1, Decay: 0.5, Recovery: 2},
+			since:   []int{0, 1, 2, 3},
+			want:    []float64{0, 0.5, 0.75, 1},
+		},
+		{name: "decay without recovery", recency: Recency{Decay: 0.5}, since: []int{0}, want: []float64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			for i, since := range tt.since {
+				if got := tt.recency.multiplier(since); math.Abs(got-tt.want[i]) > 1e-9 {
+					t.Errorf("multiplier(%d) = %v, want %v", since, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecencySelector_Cooldown(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 100), NewOption('b', 1), NewOption('c', 1))
+	rs := NewRecencySelector(s, func(int, rune) Recency { return Recency{Cooldown: 2} })
+
+	// With three Options and a cooldown of two, every window of three selections holds each once
+	var got []rune
+	for i := 0; i < 300; i++ {
+		data, err := rs.Select()
+		if err != nil {
+			t.Fatal("Select() error:", err)
+		}
+		got = append(got, data)
+	}
+	for i := 0; i+2 < len(got); i++ {
+		window := slices.Clone(got[i : i+3])
+		slices.Sort(window)
+		if !slices.Equal(window, []rune{'a', 'b', 'c'}) {
+			t.Fatalf("selections %d to %d = %c, want each Option once", i, i+2, got[i:i+3])
+		}
+	}
+}
+
+func TestRecencySelector_AllOnCooldown(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 1))
+	rs := NewRecencySelector(s, func(int, rune) Recency { return Recency{Cooldown: 1} })
+
+	if _, err := rs.Select(); err != nil {
+		t.Fatal("Select() error:", err)
+	}
+	if _, err := rs.Select(); err != ErrNoValidOptions {
+		t.Errorf("Select() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+	if got, err := rs.Select(); err != nil || got != 'a' {
+		t.Errorf("Select() after cooldown = %c, %v, want a, nil", got, err)
+	}
+
+	rs.Reset()
+	if got := rs.Probabilities(); !slices.Equal(got, []float64{1}) {
+		t.Errorf("Probabilities() after Reset() = %v, want [1]", got)
+	}
+}
+
+func TestRecencySelector_Probabilities(t *testing.T) {
+	t.Parallel()
+
+	cs := []Option[rune, int]{NewOption('a', 1), NewOption('b', 0), NewOption('c', 1)}
+	s := mustSelector(t, cs...)
+	rs := NewRecencySelector(s, func(i int, _ rune) Recency {
+		if i == 2 {
+			return Recency{Decay: 0.5, Recovery: 1}
+		}
+		return Recency{}
+	})
+
+	if got, want := rs.Probabilities(), []float64{0.5, 0.5}; !slices.Equal(got, want) {
+		t.Errorf("Probabilities() = %v, want %v", got, want)
+	}
+
+	for {
+		data, err := rs.Select()
+		if err != nil {
+			t.Fatal("Select() error:", err)
+		}
+		if data == 'c' {
+			break
+		}
+	}
+
+	if got, want := rs.Probabilities(), []float64{2.0 / 3, 1.0 / 3}; !slices.Equal(got, want) {
+		t.Errorf("Probabilities() after selecting c = %v, want %v", got, want)
+	}
+}
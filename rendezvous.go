@@ -0,0 +1,242 @@
+package weightedoption
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrInvalidWeight is returned when a weight is negative, zero, NaN or
+	// infinite where a single valid weight is required.
+	ErrInvalidWeight = errors.New("invalid weight: must be positive and finite")
+	// ErrDuplicateNode is returned by NewRendezvous, NewRendezvousFunc and
+	// Rendezvous.Set when nodes with different Data have the same key, or keys
+	// which hash the same, so they would score every key the same.
+	ErrDuplicateNode = errors.New("different nodes have the same key")
+)
+
+// rendezvousNode is a node of a Rendezvous with its precomputed seed.
+type rendezvousNode[DataType comparable, WeightType WeightConstraint] struct {
+	opt  Option[DataType, WeightType]
+	seed uint64
+}
+
+// NodeLoad is the share of a sample of keys owned by a node of a Rendezvous.
+type NodeLoad[DataType comparable, WeightType WeightConstraint] struct {
+	Data   DataType
+	Weight WeightType
+	// Keys is the number of keys in the sample the node owns.
+	Keys int
+	// Share is the fraction of the sample the node owns.
+	Share float64
+	// Expected is the fraction of keys the node's weight entitles it to.
+	Expected float64
+}
+
+// Rendezvous assigns keys to nodes with weighted rendezvous, or highest random
+// weight, hashing. Every node scores each key with a hash of the two, scaled
+// by its weight so a node owns a share of keys proportional to it, and the
+// highest scoring node owns the key. Adding, removing or reweighting a node
+// only moves keys to or from that node. Each node is scored by a key derived
+// from its Data, which must be the same in every process for keys to be
+// assigned the same way. It is safe for concurrent use.
+type Rendezvous[DataType comparable, WeightType WeightConstraint] struct {
+	mu    sync.RWMutex
+	key   func(DataType) string
+	nodes []rendezvousNode[DataType, WeightType]
+}
+
+// NewRendezvous creates a new Rendezvous with a node for each of the provided
+// Options, keyed by fmt.Sprint of their Data. That suits strings, numbers and
+// other Data which print the same in every process; for pointers, or
+// interfaces holding values of different types which may print the same, use
+// NewRendezvousFunc. Options with a negative, zero, NaN or infinite weight are
+// ignored and if none are left ErrNoValidOptions is returned. When several
+// Options have the same Data the weight of the last one is used, as with Set,
+// and if Options with different Data have the same key ErrDuplicateNode is
+// returned.
+func NewRendezvous[DataType comparable, WeightType WeightConstraint](
+	opts ...Option[DataType, WeightType],
+) (*Rendezvous[DataType, WeightType], error) {
+	return NewRendezvousFunc(func(data DataType) string { return fmt.Sprint(data) }, opts...)
+}
+
+// NewRendezvousFunc creates a new Rendezvous like NewRendezvous, with each
+// node keyed by key applied to its Data.
+func NewRendezvousFunc[DataType comparable, WeightType WeightConstraint](
+	key func(DataType) string,
+	opts ...Option[DataType, WeightType],
+) (*Rendezvous[DataType, WeightType], error) {
+	r := &Rendezvous[DataType, WeightType]{key: key}
+	for _, opt := range opts {
+		if weightReason(opt.Weight) != 0 {
+			continue
+		}
+		if err := r.set(opt); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(r.nodes) == 0 {
+		return nil, ErrNoValidOptions
+	}
+	return r, nil
+}
+
+// set adds a node for the Option, or changes the weight of the node with the
+// same Data. It must be called while holding r.mu for writing.
+func (r *Rendezvous[DataType, WeightType]) set(opt Option[DataType, WeightType]) error {
+	if i := r.find(opt.Data); i >= 0 {
+		r.nodes[i].opt.Weight = opt.Weight
+		return nil
+	}
+
+	seed := HashKey("rendezvous", r.key(opt.Data))
+	if slices.ContainsFunc(r.nodes, func(n rendezvousNode[DataType, WeightType]) bool {
+		return n.seed == seed
+	}) {
+		return ErrDuplicateNode
+	}
+	r.nodes = append(r.nodes, rendezvousNode[DataType, WeightType]{opt: opt, seed: seed})
+	return nil
+}
+
+// find returns the position of the node with the provided Data, or -1.
+func (r *Rendezvous[DataType, WeightType]) find(data DataType) int {
+	return slices.IndexFunc(r.nodes, func(n rendezvousNode[DataType, WeightType]) bool {
+		return n.opt.Data == data
+	})
+}
+
+// score returns the node's score for the key with the provided hash.
+func (n *rendezvousNode[DataType, WeightType]) score(keyHash uint64) float64 {
+	return rendezvousScore(float64(n.opt.Weight), keyHash^n.seed)
+}
+
+// rendezvousScore returns the score of hash, which combines a key with a node,
+// for a node with the provided weight. The scores of the nodes are distributed
+// so each is highest with a probability proportional to its weight.
+func rendezvousScore(weight float64, hash uint64) float64 {
+	// The top 53 bits give a uniform float64 in (0, 1), never 0 or 1
+	u := (float64(splitmix64(hash)>>11) + 0.5) / (1 << 53)
+	return -weight / math.Log(u)
+}
+
+// Owner returns the Data of the node which owns key.
+func (r *Rendezvous[DataType, WeightType]) Owner(key string) DataType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keyHash := HashKey("", key)
+	best, bestScore := 0, math.Inf(-1)
+	for i := range r.nodes {
+		if score := r.nodes[i].score(keyHash); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return r.nodes[best].opt.Data
+}
+
+// TopN returns the Data of the n nodes which score key highest, owner first,
+// for placing replicas. If there are fewer than n nodes all of them are
+// returned.
+func (r *Rendezvous[DataType, WeightType]) TopN(key string, n int) []DataType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type scored struct {
+		data  DataType
+		score float64
+	}
+	keyHash := HashKey("", key)
+	scores := make([]scored, len(r.nodes))
+	for i := range r.nodes {
+		scores[i] = scored{data: r.nodes[i].opt.Data, score: r.nodes[i].score(keyHash)}
+	}
+	slices.SortStableFunc(scores, func(a, b scored) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	top := make([]DataType, min(max(n, 0), len(scores)))
+	for i := range top {
+		top[i] = scores[i].data
+	}
+	return top
+}
+
+// Set adds a node for the Option, or changes the weight of the node with the
+// same Data. If the weight is negative, zero, NaN or infinite ErrInvalidWeight
+// is returned, and if a node with different Data has the same key
+// ErrDuplicateNode is returned.
+func (r *Rendezvous[DataType, WeightType]) Set(opt Option[DataType, WeightType]) error {
+	if weightReason(opt.Weight) != 0 {
+		return ErrInvalidWeight
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.set(opt)
+}
+
+// Remove removes the node with the provided Data and reports whether it was
+// removed. The last node can't be removed.
+func (r *Rendezvous[DataType, WeightType]) Remove(data DataType) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(data)
+	if i < 0 || len(r.nodes) == 1 {
+		return false
+	}
+	r.nodes = slices.Delete(r.nodes, i, i+1)
+	return true
+}
+
+// Nodes returns an Option for every node, in the order they were added.
+func (r *Rendezvous[DataType, WeightType]) Nodes() []Option[DataType, WeightType] {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	opts := make([]Option[DataType, WeightType], len(r.nodes))
+	for i, n := range r.nodes {
+		opts[i] = n.opt
+	}
+	return opts
+}
+
+// Load measures how a sample of keys is distributed over the nodes, returning
+// a NodeLoad for every node in the order they were added. Comparing each
+// Share with its Expected share shows how far the sample is from the weights.
+func (r *Rendezvous[DataType, WeightType]) Load(keys iter.Seq[string]) []NodeLoad[DataType, WeightType] {
+	counts := make(map[DataType]int)
+	total := 0
+	for key := range keys {
+		counts[r.Owner(key)]++
+		total++
+	}
+
+	nodes := r.Nodes()
+	var totalWeight float64
+	for _, n := range nodes {
+		totalWeight += float64(n.Weight)
+	}
+
+	loads := make([]NodeLoad[DataType, WeightType], len(nodes))
+	for i, n := range nodes {
+		loads[i] = NodeLoad[DataType, WeightType]{
+			Data:     n.Data,
+			Weight:   n.Weight,
+			Keys:     counts[n.Data],
+			Expected: float64(n.Weight) / totalWeight,
+		}
+		if total > 0 {
+			loads[i].Share = float64(counts[n.Data]) / float64(total)
+		}
+	}
+	return loads
+}
@@ -0,0 +1,200 @@
+package weightedoption
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func testKeys(n int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := range n {
+			if !yield("key-" + strconv.Itoa(i)) {
+				return
+			}
+		}
+	}
+}
+
+func TestNewRendezvous(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewRendezvous(NewOption("a", 0), NewOption("b", -1)); err != ErrNoValidOptions {
+		t.Errorf("NewRendezvous() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+
+	r, err := NewRendezvous(NewOption("a", 1), NewOption("b", 0), NewOption("a", 3))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+	want := []Option[string, int]{{Data: "a", Weight: 3}}
+	if got := r.Nodes(); !slices.Equal(got, want) {
+		t.Errorf("Nodes() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRendezvousDuplicateNode(t *testing.T) {
+	t.Parallel()
+
+	// 1 and "1" are different Data which print the same
+	if _, err := NewRendezvous(NewOption[any](1, 1), NewOption[any]("1", 1)); err != ErrDuplicateNode {
+		t.Errorf("NewRendezvous() error = %v, wantErr %v", err, ErrDuplicateNode)
+	}
+
+	key := func(data any) string { return fmt.Sprintf("%T:%v", data, data) }
+	r, err := NewRendezvousFunc(key, NewOption[any](1, 1), NewOption[any]("1", 1))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+	if got := len(r.Nodes()); got != 2 {
+		t.Errorf("Nodes() has %d nodes, want 2", got)
+	}
+	if err := r.Set(NewOption[any](int64(1), 1)); err != nil {
+		t.Errorf("Set() with a distinct key error = %v", err)
+	}
+
+	type node struct{ name string }
+	byName := func(n *node) string { return n.name }
+	r2, err := NewRendezvousFunc(byName, NewOption(&node{"a"}, 1))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+	if err := r2.Set(NewOption(&node{"a"}, 1)); err != ErrDuplicateNode {
+		t.Errorf("Set() with a duplicate key error = %v, wantErr %v", err, ErrDuplicateNode)
+	}
+	if got := len(r2.Nodes()); got != 1 {
+		t.Errorf("Nodes() after a rejected Set() has %d nodes, want 1", got)
+	}
+}
+
+func TestRendezvous_Load(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRendezvous(NewOption("small", 1.0), NewOption("medium", 2.0), NewOption("large", 5.0))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+
+	loads := r.Load(testKeys(80_000))
+	total := 0
+	for _, load := range loads {
+		total += load.Keys
+		if diff := load.Share - load.Expected; diff < -0.01 || diff > 0.01 {
+			t.Errorf("%s owns %.3f of keys, want %.3f", load.Data, load.Share, load.Expected)
+		}
+	}
+	if total != 80_000 {
+		t.Errorf("Load() counted %d keys, want 80000", total)
+	}
+}
+
+func TestRendezvous_TopN(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRendezvous(NewOption("a", 1), NewOption("b", 1), NewOption("c", 1))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "negative", n: -1, want: 0},
+		{name: "two", n: 2, want: 2},
+		{name: "more than nodes", n: 5, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			for key := range testKeys(100) {
+				top := r.TopN(key, tt.n)
+				if len(top) != tt.want {
+					t.Fatalf("TopN(%q, %d) returned %d nodes, want %d", key, tt.n, len(top), tt.want)
+				}
+				if len(top) > 0 && top[0] != r.Owner(key) {
+					t.Fatalf("TopN(%q, %d)[0] = %s, want the owner %s", key, tt.n, top[0], r.Owner(key))
+				}
+				if len(slices.Compact(slices.Sorted(slices.Values(top)))) != len(top) {
+					t.Fatalf("TopN(%q, %d) = %v, which has duplicates", key, tt.n, top)
+				}
+			}
+		})
+	}
+}
+
+func TestRendezvous_MinimalMovement(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRendezvous(NewOption("a", 1), NewOption("b", 2), NewOption("c", 3))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+	owners := func() map[string]string {
+		m := make(map[string]string)
+		for key := range testKeys(10_000) {
+			m[key] = r.Owner(key)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+		node   string
+	}{
+		{name: "add", change: func() error { return r.Set(NewOption("d", 2)) }, node: "d"},
+		{name: "reweight", change: func() error { return r.Set(NewOption("b", 4)) }, node: "b"},
+		{name: "remove", change: func() error {
+			if !r.Remove("c") {
+				t.Fatal("Remove(c) = false, want true")
+			}
+			return nil
+		}, node: "c"},
+	}
+	// Each change builds on the last, so the subtests must run in order
+	for _, tt := range tests {
+		before := owners()
+		if err := tt.change(); err != nil {
+			t.Fatalf("%s: change error: %v", tt.name, err)
+		}
+		moved := 0
+		for key, owner := range owners() {
+			if owner == before[key] {
+				continue
+			}
+			moved++
+			if owner != tt.node && before[key] != tt.node {
+				t.Fatalf("%s: %s moved from %s to %s, want only moves to or from %s", tt.name, key, before[key], owner, tt.node)
+			}
+		}
+		if moved == 0 {
+			t.Errorf("%s: no keys moved", tt.name)
+		}
+	}
+}
+
+func TestRendezvous_SetRemove(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRendezvous(NewOption("a", 1))
+	if err != nil {
+		t.Fatal("Failed to create Rendezvous:", err)
+	}
+
+	if err := r.Set(NewOption("b", 0)); err != ErrInvalidWeight {
+		t.Errorf("Set() with zero weight error = %v, wantErr %v", err, ErrInvalidWeight)
+	}
+	if r.Remove("a") {
+		t.Error("Remove() removed the last node")
+	}
+	if r.Remove("missing") {
+		t.Error("Remove() removed a node which doesn't exist")
+	}
+	if got := r.Owner("key"); got != "a" {
+		t.Errorf("Owner() = %s, want a", got)
+	}
+}
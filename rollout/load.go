@@ -0,0 +1,38 @@
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// file is the format of a flag definition file.
+type file struct {
+	Flags []Flag `json:"flags"`
+}
+
+// Load creates a new Flags from the JSON flag definitions read from r, in the
+// form {"flags": [...]} where each element is a Flag. Unknown fields are
+// rejected to catch typos. The same rules as New apply to the definitions.
+func Load(r io.Reader) (*Flags, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("decoding flags: %w", err)
+	}
+	return New(f.Flags...)
+}
+
+// LoadFile creates a new Flags from the JSON flag definition file at path, as
+// Load does.
+func LoadFile(path string) (*Flags, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening flags: %w", err)
+	}
+	defer r.Close()
+	return Load(r)
+}
@@ -0,0 +1,82 @@
+package rollout
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDefinitions = `{
+	"flags": [
+		{
+			"key": "new-checkout",
+			"rules": [{"attribute": "country", "values": ["NZ"], "variant": "on"}],
+			"variants": [
+				{"name": "off", "weight": 90, "value": {"color": "blue"}},
+				{"name": "on", "weight": 10.5}
+			]
+		}
+	]
+}`
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		errIs   error
+	}{
+		{name: "valid", input: testDefinitions},
+		{name: "invalid flag", input: `{"flags": [{"key": "f", "variants": []}]}`, wantErr: true, errIs: ErrInvalidFlag},
+		{name: "unknown field", input: `{"flags": [], "flag": []}`, wantErr: true},
+		{name: "malformed", input: `{"flags": [`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := Load(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := f.Evaluate("new-checkout", Context{Key: "u", Attributes: map[string]string{"country": "NZ"}})
+			if err != nil || got.Variant != "on" {
+				t.Errorf("Evaluate() = %+v, %v, want on", got, err)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(testDefinitions), 0o600); err != nil {
+		t.Fatal("Failed to write flags:", err)
+	}
+
+	f, err := LoadFile(path)
+	if err != nil {
+		t.Fatal("LoadFile() error:", err)
+	}
+	got, err := f.Evaluate("new-checkout", Context{Key: "u"})
+	if err != nil {
+		t.Fatal("Evaluate() error:", err)
+	}
+	if got.Variant == "off" {
+		if value, ok := got.Value.(map[string]any); !ok || value["color"] != "blue" {
+			t.Errorf("Evaluate() value = %v, want the decoded JSON value", got.Value)
+		}
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadFile() error = %v, wantErr %v", err, os.ErrNotExist)
+	}
+}
@@ -0,0 +1,212 @@
+// Package rollout evaluates feature flags locally, serving each flag's
+// variants by weight with sticky hashing so a unit always gets the same
+// variant, after any targeting rules which match its attributes.
+package rollout
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+var (
+	// ErrUnknownFlag is returned by Flags.Evaluate for a flag which isn't defined.
+	ErrUnknownFlag = errors.New("unknown flag")
+	// ErrInvalidFlag is returned by New and Load for a flag definition which
+	// can't be evaluated.
+	ErrInvalidFlag = errors.New("invalid flag")
+)
+
+// Operator decides how a Rule compares an attribute with its Values.
+type Operator string
+
+const (
+	// OperatorIn matches when the attribute is one of the Values. It is the
+	// default.
+	OperatorIn Operator = "in"
+	// OperatorNotIn matches when the attribute is missing or isn't one of the
+	// Values.
+	OperatorNotIn Operator = "not_in"
+)
+
+// Rule serves a Variant to every unit whose attribute matches, before the
+// weighted split.
+type Rule struct {
+	Attribute string   `json:"attribute"`
+	Operator  Operator `json:"operator,omitempty"`
+	Values    []string `json:"values"`
+	Variant   string   `json:"variant"`
+}
+
+// matches reports whether the Rule matches the attributes.
+func (r *Rule) matches(attributes map[string]string) bool {
+	value, ok := attributes[r.Attribute]
+	in := ok && slices.Contains(r.Values, value)
+	if r.Operator == OperatorNotIn {
+		return !in
+	}
+	return in
+}
+
+// Variant is one of the values a Flag can serve. A Variant with a Weight of 0
+// is only served by Rules.
+type Variant struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Value  any     `json:"value,omitempty"`
+}
+
+// Flag is a feature flag definition. Rules are checked in order and the first
+// which matches decides the Variant; otherwise the Variants are split by
+// weight. Salt is hashed with each unit's key to pick its Variant in the
+// split, and defaults to Key. Changing it reshuffles every unit. The split
+// depends only on the Variants' weights relative to each other, whatever their
+// scale or precision. Variants are identified by their position, so when one
+// Variant's weight changes, or a Variant is added at the end, units only move
+// to or from that Variant: no unit loses a Variant as its rollout widens, and
+// units of the other Variants stay where they are.
+type Flag struct {
+	Key      string    `json:"key"`
+	Salt     string    `json:"salt,omitempty"`
+	Rules    []Rule    `json:"rules,omitempty"`
+	Variants []Variant `json:"variants"`
+}
+
+// Context is the unit a Flag is evaluated for.
+type Context struct {
+	// Key identifies the unit, such as a user ID, and keeps its Variant the
+	// same across evaluations.
+	Key        string
+	Attributes map[string]string
+}
+
+// Reason explains why an Evaluation served its Variant.
+type Reason string
+
+const (
+	// ReasonRule is used when a Rule matched.
+	ReasonRule Reason = "rule"
+	// ReasonSplit is used when the Variant was picked by the weighted split.
+	ReasonSplit Reason = "split"
+)
+
+// Evaluation is the result of evaluating a Flag for a Context.
+type Evaluation struct {
+	Flag    string
+	Variant string
+	Value   any
+	Reason  Reason
+	// RuleIndex is the index of the Rule which matched, or -1.
+	RuleIndex int
+}
+
+// compiledFlag is a Flag with its Rules resolved to Variants and a Selector
+// for its split.
+type compiledFlag struct {
+	flag         Flag
+	ruleVariants []int
+	split        *weightedoption.Selector[int, float64]
+}
+
+// Flags is a set of Flags which can be evaluated locally. It is safe for
+// concurrent use.
+type Flags struct {
+	flags map[string]*compiledFlag
+	keys  []string
+}
+
+// New creates a new Flags from the provided definitions. If a definition has no
+// key, repeats a key or a Variant name, has a Rule serving a Variant it doesn't
+// define, or has no Variant with a positive weight, an error wrapping
+// ErrInvalidFlag is returned.
+func New(defs ...Flag) (*Flags, error) {
+	f := &Flags{flags: make(map[string]*compiledFlag, len(defs))}
+	for _, def := range defs {
+		if def.Key == "" {
+			return nil, fmt.Errorf("%w: missing key", ErrInvalidFlag)
+		}
+		if _, ok := f.flags[def.Key]; ok {
+			return nil, fmt.Errorf("%w %q: defined more than once", ErrInvalidFlag, def.Key)
+		}
+
+		compiled, err := compile(def)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidFlag, def.Key, err)
+		}
+		f.flags[def.Key] = compiled
+		f.keys = append(f.keys, def.Key)
+	}
+	return f, nil
+}
+
+// compile resolves a Flag's Rules and builds the Selector for its split.
+func compile(def Flag) (*compiledFlag, error) {
+	if def.Salt == "" {
+		def.Salt = def.Key
+	}
+
+	names := make(map[string]int, len(def.Variants))
+	opts := make([]weightedoption.Option[int, float64], len(def.Variants))
+	for i, v := range def.Variants {
+		if _, ok := names[v.Name]; ok {
+			return nil, fmt.Errorf("variant %q defined more than once", v.Name)
+		}
+		names[v.Name] = i
+		opts[i] = weightedoption.NewOption(i, v.Weight)
+	}
+
+	ruleVariants := make([]int, len(def.Rules))
+	for i, rule := range def.Rules {
+		v, ok := names[rule.Variant]
+		if !ok {
+			return nil, fmt.Errorf("rule %d serves unknown variant %q", i, rule.Variant)
+		}
+		if rule.Operator != "" && rule.Operator != OperatorIn && rule.Operator != OperatorNotIn {
+			return nil, fmt.Errorf("rule %d has unknown operator %q", i, rule.Operator)
+		}
+		ruleVariants[i] = v
+	}
+
+	split, err := weightedoption.NewSelector(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &compiledFlag{flag: def, ruleVariants: ruleVariants, split: split}, nil
+}
+
+// Keys returns the keys of every Flag, in the order they were defined.
+func (f *Flags) Keys() []string {
+	return slices.Clone(f.keys)
+}
+
+// Evaluate returns the Variant the Flag with the provided key serves for ctx.
+// If there is no such Flag ErrUnknownFlag is returned.
+func (f *Flags) Evaluate(key string, ctx Context) (Evaluation, error) {
+	compiled, ok := f.flags[key]
+	if !ok {
+		return Evaluation{}, fmt.Errorf("%w: %q", ErrUnknownFlag, key)
+	}
+
+	for i := range compiled.flag.Rules {
+		if compiled.flag.Rules[i].matches(ctx.Attributes) {
+			return compiled.evaluation(compiled.ruleVariants[i], ReasonRule, i), nil
+		}
+	}
+
+	v := compiled.split.SelectByHash(weightedoption.HashKey(compiled.flag.Salt, ctx.Key))
+	return compiled.evaluation(v, ReasonSplit, -1), nil
+}
+
+// evaluation returns the Evaluation serving the Variant at index v.
+func (c *compiledFlag) evaluation(v int, reason Reason, rule int) Evaluation {
+	variant := c.flag.Variants[v]
+	return Evaluation{
+		Flag:      c.flag.Key,
+		Variant:   variant.Name,
+		Value:     variant.Value,
+		Reason:    reason,
+		RuleIndex: rule,
+	}
+}
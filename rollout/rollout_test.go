@@ -0,0 +1,203 @@
+package rollout
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+var testFlag = Flag{
+	Key: "new-checkout",
+	Rules: []Rule{
+		{Attribute: "country", Values: []string{"NZ", "AU"}, Variant: "on"},
+		{Attribute: "plan", Operator: OperatorNotIn, Values: []string{"pro", "free"}, Variant: "off"},
+	},
+	Variants: []Variant{
+		{Name: "off", Weight: 75, Value: false},
+		{Name: "on", Weight: 25, Value: true},
+	},
+}
+
+func mustFlags(t *testing.T, defs ...Flag) *Flags {
+	t.Helper()
+	f, err := New(defs...)
+	if err != nil {
+		t.Fatal("Failed to create Flags:", err)
+	}
+	return f
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	variants := []Variant{{Name: "a", Weight: 1}}
+	tests := []struct {
+		name string
+		defs []Flag
+	}{
+		{name: "missing key", defs: []Flag{{Variants: variants}}},
+		{name: "duplicate key", defs: []Flag{{Key: "f", Variants: variants}, {Key: "f", Variants: variants}}},
+		{name: "duplicate variant", defs: []Flag{{Key: "f", Variants: []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}}}},
+		{name: "unknown rule variant", defs: []Flag{{Key: "f", Variants: variants, Rules: []Rule{{Attribute: "x", Values: []string{"y"}, Variant: "b"}}}}},
+		{name: "unknown operator", defs: []Flag{{Key: "f", Variants: variants, Rules: []Rule{{Attribute: "x", Operator: "like", Variant: "a"}}}}},
+		{name: "no weighted variant", defs: []Flag{{Key: "f", Variants: []Variant{{Name: "a", Weight: 0}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := New(tt.defs...); !errors.Is(err, ErrInvalidFlag) {
+				t.Errorf("New() error = %v, wantErr %v", err, ErrInvalidFlag)
+			}
+		})
+	}
+
+	f := mustFlags(t, Flag{Key: "b", Variants: variants}, Flag{Key: "a", Variants: variants})
+	if got := f.Keys(); !slices.Equal(got, []string{"b", "a"}) {
+		t.Errorf("Keys() = %v, want [b a]", got)
+	}
+}
+
+func TestFlags_EvaluateRules(t *testing.T) {
+	t.Parallel()
+
+	f := mustFlags(t, testFlag)
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		want       Evaluation
+	}{
+		{
+			name:       "in",
+			attributes: map[string]string{"country": "NZ", "plan": "pro"},
+			want:       Evaluation{Flag: "new-checkout", Variant: "on", Value: true, Reason: ReasonRule, RuleIndex: 0},
+		},
+		{
+			name:       "not in",
+			attributes: map[string]string{"country": "GB", "plan": "enterprise"},
+			want:       Evaluation{Flag: "new-checkout", Variant: "off", Value: false, Reason: ReasonRule, RuleIndex: 1},
+		},
+		{
+			name:       "not in with missing attribute",
+			attributes: nil,
+			want:       Evaluation{Flag: "new-checkout", Variant: "off", Value: false, Reason: ReasonRule, RuleIndex: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := f.Evaluate("new-checkout", Context{Key: "user", Attributes: tt.attributes})
+			if err != nil {
+				t.Fatal("Evaluate() error:", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := f.Evaluate("missing", Context{}); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("Evaluate() error = %v, wantErr %v", err, ErrUnknownFlag)
+	}
+}
+
+func TestFlags_EvaluateSplit(t *testing.T) {
+	t.Parallel()
+
+	f := mustFlags(t, testFlag)
+	attributes := map[string]string{"plan": "free"}
+
+	on := 0
+	const n = 20_000
+	for i := range n {
+		ctx := Context{Key: "user-" + strconv.Itoa(i), Attributes: attributes}
+		got, err := f.Evaluate("new-checkout", ctx)
+		if err != nil {
+			t.Fatal("Evaluate() error:", err)
+		}
+		if got.Reason != ReasonSplit || got.RuleIndex != -1 {
+			t.Fatalf("Evaluate() = %+v, want the split to decide", got)
+		}
+		if again, _ := f.Evaluate("new-checkout", ctx); again != got {
+			t.Fatalf("Evaluate() for %s = %s then %s, want the same variant", ctx.Key, got.Variant, again.Variant)
+		}
+		if got.Variant == "on" {
+			on++
+		}
+	}
+
+	if got := float64(on) / n; got < 0.23 || got > 0.27 {
+		t.Errorf("on was served to %.3f of units, want 0.25", got)
+	}
+}
+
+func TestFlags_EvaluateRampUp(t *testing.T) {
+	t.Parallel()
+
+	ramp := func(off, on float64) *Flags {
+		def := Flag{Key: "ramp", Variants: []Variant{{Name: "off", Weight: off}, {Name: "on", Weight: on}}}
+		return mustFlags(t, def)
+	}
+
+	tests := []struct {
+		name          string
+		before, after *Flags
+	}{
+		{name: "same total", before: ramp(90, 10), after: ramp(50, 50)},
+		{name: "extra decimal digit", before: ramp(95, 5), after: ramp(87.5, 12.5)},
+		{name: "different total", before: ramp(50, 10), after: ramp(50, 20)},
+		{name: "float to whole weights", before: ramp(99.9, 0.1), after: ramp(3, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			on := 0
+			for i := range 100_000 {
+				ctx := Context{Key: strconv.Itoa(i)}
+				b, _ := tt.before.Evaluate("ramp", ctx)
+				a, _ := tt.after.Evaluate("ramp", ctx)
+				if b.Variant != "on" {
+					continue
+				}
+				on++
+				if a.Variant != "on" {
+					t.Fatalf("unit %s was turned off by widening the rollout", ctx.Key)
+				}
+			}
+			if on == 0 {
+				t.Error("no unit was on before widening the rollout")
+			}
+		})
+	}
+}
+
+func TestFlags_EvaluateResizeOther(t *testing.T) {
+	t.Parallel()
+
+	experiment := func(holdout float64) *Flags {
+		def := Flag{Key: "exp", Variants: []Variant{
+			{Name: "control", Weight: 45},
+			{Name: "treatment", Weight: 45},
+			{Name: "holdout", Weight: holdout},
+		}}
+		return mustFlags(t, def)
+	}
+
+	before, after := experiment(10), experiment(30)
+	moved := 0
+	for i := range 100_000 {
+		ctx := Context{Key: strconv.Itoa(i)}
+		b, _ := before.Evaluate("exp", ctx)
+		a, _ := after.Evaluate("exp", ctx)
+		if a.Variant == b.Variant {
+			continue
+		}
+		moved++
+		if a.Variant != "holdout" {
+			t.Fatalf("unit %s moved from %s to %s when only holdout was resized", ctx.Key, b.Variant, a.Variant)
+		}
+	}
+	if moved == 0 {
+		t.Error("no unit moved to the widened holdout")
+	}
+}
@@ -0,0 +1,178 @@
+package weightedoption
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+	"sync"
+)
+
+// RoundRobin returns Options in the deterministic, evenly interleaved order of
+// nginx's smooth weighted round-robin, rather than at random. Over every period
+// of the total weight each Option is returned exactly as many times as its
+// weight, e.g. weights 5, 1 and 1 give a a b a c a a. It is safe for
+// concurrent use.
+type RoundRobin[DataType any, WeightType WeightConstraint] struct {
+	mu      sync.Mutex
+	opts    []Option[DataType, WeightType]
+	weights []int
+	current []int
+	total   int
+}
+
+// NewRoundRobin creates a new RoundRobin for the provided Options. The same
+// rules as NewSelector apply to the Options, except Options which can't be
+// selected are kept so that SetWeight can enable them later.
+func NewRoundRobin[DataType any, WeightType WeightConstraint](
+	opts ...Option[DataType, WeightType],
+) (*RoundRobin[DataType, WeightType], error) {
+	rr := &RoundRobin[DataType, WeightType]{
+		opts:    slices.Clone(opts),
+		current: make([]int, len(opts)),
+	}
+	if err := rr.reweigh(); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// reweigh converts the Options' weights to integers. It must be called while
+// holding rr.mu, and leaves rr unchanged if it returns an error.
+func (rr *RoundRobin[DataType, WeightType]) reweigh() error {
+	_, indices, scaled, err := prepareOptions(-1, rr.opts...)
+	if err != nil {
+		return err
+	}
+
+	s, err := newSelector[DataType, WeightType](nil, indices, scaled)
+	if err != nil {
+		return err
+	}
+
+	weights := make([]int, len(rr.opts))
+	for i, index := range indices {
+		weights[index] = int(scaled[i])
+	}
+	rr.weights = weights
+	rr.total = int(s.totalWeight)
+	return nil
+}
+
+// Next returns the next DataType in the sequence.
+func (rr *RoundRobin[DataType, WeightType]) Next() DataType {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	best := -1
+	for i, w := range rr.weights {
+		if w == 0 {
+			continue
+		}
+		rr.current[i] += w
+		if best < 0 || rr.current[i] > rr.current[best] {
+			best = i
+		}
+	}
+	rr.current[best] -= rr.total
+	return rr.opts[best].Data
+}
+
+// Stream returns an endless sequence of DataType from Next.
+func (rr *RoundRobin[DataType, WeightType]) Stream() iter.Seq[DataType] {
+	return stream(rr.Next)
+}
+
+// Take returns a sequence of the next n DataType from Next.
+func (rr *RoundRobin[DataType, WeightType]) Take(n int) iter.Seq[DataType] {
+	return take(rr.Next, n)
+}
+
+// Period returns the total weight, the length of the sequence over which every
+// Option is returned exactly as many times as its weight.
+func (rr *RoundRobin[DataType, WeightType]) Period() int {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return rr.total
+}
+
+// SetWeight changes the weight of the Option at index i in the Options the
+// RoundRobin was created from. The position of every Option in the current
+// cycle is kept, scaled to the new total weight, so the sequence adjusts
+// smoothly to the new weights instead of restarting. If there is no such
+// Option ErrIndexOutOfRange is returned. If no Option could be selected with
+// the new weight the weight is left unchanged and ErrNoValidOptions is
+// returned; other errors are the same as NewSelector's.
+func (rr *RoundRobin[DataType, WeightType]) SetWeight(i int, weight WeightType) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if i < 0 || i >= len(rr.opts) {
+		return ErrIndexOutOfRange
+	}
+	old, oldTotal := rr.opts[i].Weight, rr.total
+	rr.opts[i].Weight = weight
+	if err := rr.reweigh(); err != nil {
+		rr.opts[i].Weight = old
+		return err
+	}
+
+	rr.rebalance(oldTotal)
+	return nil
+}
+
+// rebalance scales the position of every Option in the current cycle from
+// oldTotal to the new total weight, and spreads the positions of Options
+// which can no longer be selected over the others, keeping the positions
+// summing to zero as smooth weighted round-robin requires. It must be called
+// while holding rr.mu.
+func (rr *RoundRobin[DataType, WeightType]) rebalance(oldTotal int) {
+	var sum, active int
+	for j, w := range rr.weights {
+		if w == 0 {
+			rr.current[j] = 0
+			continue
+		}
+		rr.current[j] = scaleCounter(rr.current[j], rr.total, oldTotal)
+		sum += rr.current[j]
+		active++
+	}
+
+	// Rounding and removed Options leave sum over, so take it back evenly
+	share, extra := sum/active, sum%active
+	for j, w := range rr.weights {
+		if w == 0 {
+			continue
+		}
+		rr.current[j] -= share
+		if extra > 0 {
+			rr.current[j]--
+			extra--
+		} else if extra < 0 {
+			rr.current[j]++
+			extra++
+		}
+	}
+}
+
+// scaleCounter returns c * newTotal / oldTotal rounded towards zero, without
+// overflowing when c is at most oldTotal in magnitude.
+func scaleCounter(c, newTotal, oldTotal int) int {
+	if c == 0 || newTotal == oldTotal {
+		return c
+	}
+	magnitude := uint64(c)
+	if c < 0 {
+		magnitude = uint64(-c)
+	}
+	hi, lo := bits.Mul64(magnitude, uint64(newTotal))
+	if hi >= uint64(oldTotal) {
+		// A counter beyond oldTotal is clamped to the new total
+		magnitude = uint64(newTotal)
+	} else {
+		magnitude, _ = bits.Div64(hi, lo, uint64(oldTotal))
+	}
+	if c < 0 {
+		return -int(magnitude)
+	}
+	return int(magnitude)
+}
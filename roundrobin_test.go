@@ -0,0 +1,202 @@
+package weightedoption
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRoundRobin_Next(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cs   []Option[rune, int]
+		want string
+	}{
+		{
+			name: "nginx example",
+			cs:   []Option[rune, int]{{Data: 'a', Weight: 5}, {Data: 'b', Weight: 1}, {Data: 'c', Weight: 1}},
+			want: "aabacaa",
+		},
+		{
+			name: "equal weights",
+			cs:   []Option[rune, int]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 1}, {Data: 'c', Weight: 1}},
+			want: "abc",
+		},
+		{
+			name: "invalid options skipped",
+			cs:   []Option[rune, int]{{Data: 'a', Weight: 2}, {Data: 'b', Weight: 0}, {Data: 'c', Weight: -1}, {Data: 'd', Weight: 1}},
+			want: "ada",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr, err := NewRoundRobin(tt.cs...)
+			if err != nil {
+				t.Fatal("Failed to create RoundRobin:", err)
+			}
+			if got := rr.Period(); got != len(tt.want) {
+				t.Errorf("Period() = %d, want %d", got, len(tt.want))
+			}
+			// The sequence repeats every period
+			for range 3 {
+				if got := string(slices.Collect(rr.Take(len(tt.want)))); got != tt.want {
+					t.Errorf("Take(%d) = %q, want %q", len(tt.want), got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewRoundRobin(NewOption('a', 0)); err != ErrNoValidOptions {
+		t.Errorf("NewRoundRobin() error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+
+	rr, err := NewRoundRobin(NewOption('a', 0.5), NewOption('b', 0.25))
+	if err != nil {
+		t.Fatal("Failed to create RoundRobin:", err)
+	}
+	if got, want := string(slices.Collect(rr.Take(3))), "aba"; got != want {
+		t.Errorf("Take(3) = %q, want %q", got, want)
+	}
+}
+
+func TestRoundRobin_SetWeight(t *testing.T) {
+	t.Parallel()
+
+	rr, err := NewRoundRobin(NewOption('a', 1), NewOption('b', 1), NewOption('c', 0))
+	if err != nil {
+		t.Fatal("Failed to create RoundRobin:", err)
+	}
+	if got := rr.Next(); got != 'a' {
+		t.Fatalf("Next() = %c, want a", got)
+	}
+
+	// b keeps its place in the cycle rather than starting again from a
+	if err := rr.SetWeight(2, 1); err != nil {
+		t.Fatal("SetWeight() error:", err)
+	}
+	if got, want := string(slices.Collect(rr.Take(3))), "bca"; got != want {
+		t.Errorf("Take(3) after SetWeight = %q, want %q", got, want)
+	}
+
+	if err := rr.SetWeight(0, 0); err != nil {
+		t.Fatal("SetWeight() error:", err)
+	}
+	counts := make(map[rune]int)
+	for c := range rr.Take(100) {
+		counts[c]++
+	}
+	if counts['a'] != 0 || counts['b'] != 50 || counts['c'] != 50 {
+		t.Errorf("counts after disabling a = %v, want b and c 50 each", counts)
+	}
+
+	if err := rr.SetWeight(1, 0); err != nil {
+		t.Fatal("SetWeight() error:", err)
+	}
+	if err := rr.SetWeight(2, -1); err != ErrNoValidOptions {
+		t.Errorf("SetWeight() disabling every option error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+	if got := rr.Next(); got != 'c' {
+		t.Errorf("Next() after rejected SetWeight = %c, want c", got)
+	}
+}
+
+func TestRoundRobin_SetWeightMidCycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before int
+		i      int
+		weight int
+		want   string
+	}{
+		{name: "remove the heaviest", before: 3, i: 0, weight: 0, want: "bcbcbc"},
+		{name: "shrink the heaviest", before: 3, i: 0, weight: 1, want: "cabcab"},
+		{name: "grow a light Option", before: 1, i: 1, weight: 5, want: "babacbababab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr, err := NewRoundRobin(NewOption('a', 5), NewOption('b', 1), NewOption('c', 1))
+			if err != nil {
+				t.Fatal("Failed to create RoundRobin:", err)
+			}
+			for range tt.before {
+				rr.Next()
+			}
+
+			if err := rr.SetWeight(tt.i, tt.weight); err != nil {
+				t.Fatal("SetWeight() error:", err)
+			}
+			sum := 0
+			for _, c := range rr.current {
+				sum += c
+			}
+			if sum != 0 {
+				t.Errorf("positions after SetWeight() sum to %d, want 0", sum)
+			}
+			if got := string(slices.Collect(rr.Take(len(tt.want)))); got != tt.want {
+				t.Errorf("Take(%d) after SetWeight() = %q, want %q", len(tt.want), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundRobin_SetWeightOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	rr, err := NewRoundRobin(NewOption('a', 1), NewOption('b', 1))
+	if err != nil {
+		t.Fatal("Failed to create RoundRobin:", err)
+	}
+	for _, i := range []int{-1, 2} {
+		if err := rr.SetWeight(i, 3); err != ErrIndexOutOfRange {
+			t.Errorf("SetWeight(%d, 3) error = %v, wantErr %v", i, err, ErrIndexOutOfRange)
+		}
+	}
+	if got := rr.Period(); got != 2 {
+		t.Errorf("Period() after out of range SetWeight() = %d, want 2", got)
+	}
+}
+
+func TestRoundRobin_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	rr, err := NewRoundRobin(NewOption('a', 3), NewOption('b', 1))
+	if err != nil {
+		t.Fatal("Failed to create RoundRobin:", err)
+	}
+
+	const goroutines, perGoroutine = 8, 1000
+	var mu sync.Mutex
+	counts := make(map[rune]int)
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make(map[rune]int)
+			for range perGoroutine {
+				local[rr.Next()]++
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for k, v := range local {
+				counts[k] += v
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 8000 is a whole number of periods, so the split is exact
+	if counts['a'] != 6000 || counts['b'] != 2000 {
+		t.Errorf("counts = %v, want a 6000 and b 2000", counts)
+	}
+}
@@ -0,0 +1,203 @@
+package weightedoption
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrNoActiveWindow is returned when no Window of a ScheduledSelector is active at the time of selection.
+	ErrNoActiveWindow = errors.New("no Window is active")
+	// ErrOverlappingWindows is returned by NewScheduledSelector when Windows overlap and the OverlapRule is OverlapReject.
+	ErrOverlappingWindows = errors.New("overlapping Windows")
+	// ErrInvalidWindow is returned by NewScheduledSelector for a Window without a Selector or which ends before it starts.
+	ErrInvalidWindow = errors.New("invalid Window: no Selector or ends before it starts")
+)
+
+// Window is a period of time during which a Selector is active. It is active
+// from Start, inclusive, until End, exclusive. A zero Start means the Window
+// has always been active and a zero End means it never stops being active, so
+// a Window with both zero with the lowest Priority acts as a fallback.
+type Window[DataType any, WeightType WeightConstraint] struct {
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Priority int
+	Selector *Selector[DataType, WeightType]
+}
+
+// contains reports whether the Window is active at t.
+func (w *Window[DataType, WeightType]) contains(t time.Time) bool {
+	return (w.Start.IsZero() || !t.Before(w.Start)) && (w.End.IsZero() || t.Before(w.End))
+}
+
+// overlaps reports whether the Window is active at any time o is.
+func (w *Window[DataType, WeightType]) overlaps(o *Window[DataType, WeightType]) bool {
+	startsBeforeOEnds := w.Start.IsZero() || o.End.IsZero() || w.Start.Before(o.End)
+	oStartsBeforeEnd := o.Start.IsZero() || w.End.IsZero() || o.Start.Before(w.End)
+	return startsBeforeOEnds && oStartsBeforeEnd
+}
+
+// OverlapRule decides which Window of a ScheduledSelector is active when
+// several of them are active at the same time.
+type OverlapRule int
+
+const (
+	// OverlapPriority makes the Window with the highest Priority active, then
+	// the one which started most recently, then the one provided first. It is
+	// the default.
+	OverlapPriority OverlapRule = iota
+	// OverlapLatestStart makes the Window which started most recently active,
+	// then the one provided first.
+	OverlapLatestStart
+	// OverlapReject makes NewScheduledSelector return ErrOverlappingWindows if
+	// any Windows overlap.
+	OverlapReject
+)
+
+// Transition is a point in time at which the active Window of a
+// ScheduledSelector changes. Previous and Next are nil when no Window is
+// active before or after it.
+type Transition[DataType any, WeightType WeightConstraint] struct {
+	At       time.Time
+	Previous *Window[DataType, WeightType]
+	Next     *Window[DataType, WeightType]
+}
+
+// ScheduleOption configures a ScheduledSelector created by NewScheduledSelector.
+type ScheduleOption func(*scheduleConfig)
+
+type scheduleConfig struct {
+	now  func() time.Time
+	rule OverlapRule
+}
+
+// WithClock makes the ScheduledSelector call now instead of time.Now to find
+// the current time.
+func WithClock(now func() time.Time) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.now = now
+	}
+}
+
+// WithOverlapRule sets the OverlapRule of the ScheduledSelector.
+func WithOverlapRule(rule OverlapRule) ScheduleOption {
+	return func(c *scheduleConfig) {
+		c.rule = rule
+	}
+}
+
+// ScheduledSelector selects from the Selector of whichever of its Windows is
+// active at the time of selection, for example to rotate banners with
+// weekend boosts and limited-time rate-ups.
+type ScheduledSelector[DataType any, WeightType WeightConstraint] struct {
+	windows []Window[DataType, WeightType]
+	now     func() time.Time
+	rule    OverlapRule
+}
+
+// NewScheduledSelector creates a new ScheduledSelector for the provided Windows,
+// configured by the provided ScheduleOptions.
+func NewScheduledSelector[DataType any, WeightType WeightConstraint](
+	windows []Window[DataType, WeightType],
+	cfg ...ScheduleOption,
+) (*ScheduledSelector[DataType, WeightType], error) {
+	c := scheduleConfig{now: time.Now, rule: OverlapPriority}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+
+	windows = slices.Clone(windows)
+	for i := range windows {
+		w := &windows[i]
+		if w.Selector == nil || (!w.Start.IsZero() && !w.End.IsZero() && w.End.Before(w.Start)) {
+			return nil, ErrInvalidWindow
+		}
+
+		if c.rule != OverlapReject {
+			continue
+		}
+		for j := range i {
+			if w.overlaps(&windows[j]) {
+				return nil, ErrOverlappingWindows
+			}
+		}
+	}
+
+	return &ScheduledSelector[DataType, WeightType]{
+		windows: windows,
+		now:     c.now,
+		rule:    c.rule,
+	}, nil
+}
+
+// ActiveAt returns the Window active at t, or nil if there is none.
+func (ss *ScheduledSelector[DataType, WeightType]) ActiveAt(t time.Time) *Window[DataType, WeightType] {
+	var active *Window[DataType, WeightType]
+	for i := range ss.windows {
+		w := &ss.windows[i]
+		if w.contains(t) && (active == nil || ss.preferred(w, active)) {
+			active = w
+		}
+	}
+	return active
+}
+
+// preferred reports whether w takes precedence over active, which was
+// provided before it.
+func (ss *ScheduledSelector[DataType, WeightType]) preferred(w, active *Window[DataType, WeightType]) bool {
+	if ss.rule == OverlapPriority && w.Priority != active.Priority {
+		return w.Priority > active.Priority
+	}
+	return w.Start.After(active.Start)
+}
+
+// Active returns the Window active now, or nil if there is none.
+func (ss *ScheduledSelector[DataType, WeightType]) Active() *Window[DataType, WeightType] {
+	return ss.ActiveAt(ss.now())
+}
+
+// Select returns a single DataType from the Selector of the Window active now.
+// If no Window is active ErrNoActiveWindow is returned.
+func (ss *ScheduledSelector[DataType, WeightType]) Select() (DataType, error) {
+	active := ss.Active()
+	if active == nil {
+		var zero DataType
+		return zero, ErrNoActiveWindow
+	}
+	return active.Selector.Select(), nil
+}
+
+// Transitions returns every Transition after from and up to and including
+// until, in chronological order.
+func (ss *ScheduledSelector[DataType, WeightType]) Transitions(from, until time.Time) []Transition[DataType, WeightType] {
+	var boundaries []time.Time
+	for _, w := range ss.windows {
+		for _, t := range []time.Time{w.Start, w.End} {
+			if !t.IsZero() && t.After(from) && !t.After(until) {
+				boundaries = append(boundaries, t)
+			}
+		}
+	}
+	slices.SortFunc(boundaries, time.Time.Compare)
+	boundaries = slices.CompactFunc(boundaries, time.Time.Equal)
+
+	var transitions []Transition[DataType, WeightType]
+	previous := ss.ActiveAt(from)
+	for _, t := range boundaries {
+		next := ss.ActiveAt(t)
+		if next != previous {
+			transitions = append(transitions, Transition[DataType, WeightType]{At: t, Previous: previous, Next: next})
+			previous = next
+		}
+	}
+	return transitions
+}
+
+// Upcoming returns every Transition from now until the provided duration has
+// passed, in chronological order.
+func (ss *ScheduledSelector[DataType, WeightType]) Upcoming(d time.Duration) []Transition[DataType, WeightType] {
+	now := ss.now()
+	return ss.Transitions(now, now.Add(d))
+}
@@ -0,0 +1,170 @@
+package weightedoption
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewScheduledSelector(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 1))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		windows []Window[rune, int]
+		rule    OverlapRule
+		wantErr error
+	}{
+		{
+			name:    "no windows",
+			windows: nil,
+		},
+		{
+			name:    "window without selector",
+			windows: []Window[rune, int]{{Start: start}},
+			wantErr: ErrInvalidWindow,
+		},
+		{
+			name:    "window ending before it starts",
+			windows: []Window[rune, int]{{Start: start, End: start.Add(-time.Hour), Selector: s}},
+			wantErr: ErrInvalidWindow,
+		},
+		{
+			name: "overlapping windows allowed",
+			windows: []Window[rune, int]{
+				{Start: start, End: start.Add(2 * time.Hour), Selector: s},
+				{Start: start.Add(time.Hour), End: start.Add(3 * time.Hour), Selector: s},
+			},
+		},
+		{
+			name: "overlapping windows rejected",
+			windows: []Window[rune, int]{
+				{Start: start, End: start.Add(2 * time.Hour), Selector: s},
+				{Start: start.Add(time.Hour), End: start.Add(3 * time.Hour), Selector: s},
+			},
+			rule:    OverlapReject,
+			wantErr: ErrOverlappingWindows,
+		},
+		{
+			name: "adjacent windows don't overlap",
+			windows: []Window[rune, int]{
+				{Start: start, End: start.Add(time.Hour), Selector: s},
+				{Start: start.Add(time.Hour), Selector: s},
+			},
+			rule: OverlapReject,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewScheduledSelector(tt.windows, WithOverlapRule(tt.rule))
+			if err != tt.wantErr {
+				t.Errorf("NewScheduledSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduledSelector_Select(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := []Window[rune, int]{
+		{Name: "standard", Priority: -1, Selector: mustSelector(t, NewOption('s', 1))},
+		{Name: "weekend", Start: start, End: start.Add(48 * time.Hour), Selector: mustSelector(t, NewOption('w', 1))},
+		{Name: "rate-up", Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour), Priority: 1, Selector: mustSelector(t, NewOption('r', 1))},
+		{Name: "late", Start: start.Add(36 * time.Hour), End: start.Add(37 * time.Hour), Selector: mustSelector(t, NewOption('l', 1))},
+	}
+
+	tests := []struct {
+		name string
+		rule OverlapRule
+		at   time.Duration
+		want rune
+	}{
+		{name: "before everything", at: -time.Hour, want: 's'},
+		{name: "weekend", at: 0, want: 'w'},
+		{name: "priority rate-up", at: 24 * time.Hour, want: 'r'},
+		{name: "equal priority latest start", at: 36 * time.Hour, want: 'l'},
+		{name: "after weekend", at: 48 * time.Hour, want: 's'},
+		{name: "latest start ignores priority", rule: OverlapLatestStart, at: 36*time.Hour + time.Minute, want: 'l'},
+		{name: "latest start with rate-up", rule: OverlapLatestStart, at: 24 * time.Hour, want: 'r'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			now := start.Add(tt.at)
+			ss, err := NewScheduledSelector(windows, WithOverlapRule(tt.rule), WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatal("Failed to create ScheduledSelector:", err)
+			}
+			got, err := ss.Select()
+			if err != nil {
+				t.Fatal("Select() error:", err)
+			}
+			if got != tt.want {
+				t.Errorf("Select() = %c, want %c", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no active window", func(t *testing.T) {
+		t.Parallel()
+		ss, err := NewScheduledSelector(windows[1:], WithClock(func() time.Time { return start.Add(-time.Hour) }))
+		if err != nil {
+			t.Fatal("Failed to create ScheduledSelector:", err)
+		}
+		if _, err := ss.Select(); err != ErrNoActiveWindow {
+			t.Errorf("Select() error = %v, wantErr %v", err, ErrNoActiveWindow)
+		}
+	})
+}
+
+func TestScheduledSelector_Transitions(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := []Window[rune, int]{
+		{Name: "weekend", Start: start, End: start.Add(48 * time.Hour), Selector: mustSelector(t, NewOption('w', 1))},
+		{Name: "rate-up", Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour), Priority: 1, Selector: mustSelector(t, NewOption('r', 1))},
+		{Name: "hidden", Start: start.Add(30 * time.Hour), End: start.Add(31 * time.Hour), Priority: -1, Selector: mustSelector(t, NewOption('h', 1))},
+	}
+	now := start.Add(-time.Hour)
+	ss, err := NewScheduledSelector(windows, WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal("Failed to create ScheduledSelector:", err)
+	}
+
+	name := func(w *Window[rune, int]) string {
+		if w == nil {
+			return ""
+		}
+		return w.Name
+	}
+
+	want := []struct {
+		at             time.Duration
+		previous, next string
+	}{
+		{at: 0, previous: "", next: "weekend"},
+		{at: 24 * time.Hour, previous: "weekend", next: "rate-up"},
+		{at: 25 * time.Hour, previous: "rate-up", next: "weekend"},
+		{at: 48 * time.Hour, previous: "weekend", next: ""},
+	}
+	got := ss.Upcoming(72 * time.Hour)
+	if len(got) != len(want) {
+		t.Fatalf("Upcoming() returned %d transitions, want %d", len(got), len(want))
+	}
+	for i, tr := range got {
+		if !tr.At.Equal(start.Add(want[i].at)) || name(tr.Previous) != want[i].previous || name(tr.Next) != want[i].next {
+			t.Errorf("Upcoming()[%d] = %v %q -> %q, want %v %q -> %q",
+				i, tr.At, name(tr.Previous), name(tr.Next), start.Add(want[i].at), want[i].previous, want[i].next)
+		}
+	}
+
+	if got := ss.Transitions(start, start.Add(24*time.Hour)); len(got) != 1 {
+		t.Errorf("Transitions() returned %d transitions, want 1", len(got))
+	}
+}
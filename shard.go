@@ -0,0 +1,101 @@
+package weightedoption
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// WithSource returns a copy of the Selector which draws from src instead of
+// the global random number generator, as if it had been created with the
+// WithSource SelectorOption. The copy shares its Options with s. As src is
+// usually not safe for concurrent use, neither is the returned Selector.
+func (s *Selector[DataType, WeightType]) WithSource(src rand.Source) *Selector[DataType, WeightType] {
+	c := *s
+	c.rng = rand.New(src)
+	return &c
+}
+
+// ShardedSelector holds one Selector per shard, each drawing from its own
+// independent random number generator. Giving each worker its own shard
+// removes contention on the global random number generator, and seeding the
+// ShardedSelector makes every shard's sequence of draws reproducible.
+type ShardedSelector[DataType any, WeightType WeightConstraint] struct {
+	shards []*Selector[DataType, WeightType]
+}
+
+// NewShardedSelector creates a new ShardedSelector with n shards of s. Shard i
+// draws from a PCG generator seeded with seed and i. If n is less than 1,
+// runtime.GOMAXPROCS(0) shards are created.
+func NewShardedSelector[DataType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	n int,
+	seed uint64,
+) *ShardedSelector[DataType, WeightType] {
+	if n < 1 {
+		n = runtime.GOMAXPROCS(0)
+	}
+
+	shards := make([]*Selector[DataType, WeightType], n)
+	for i := range shards {
+		shards[i] = s.WithSource(rand.NewPCG(seed, splitmix64(uint64(i))))
+	}
+
+	return &ShardedSelector[DataType, WeightType]{shards: shards}
+}
+
+// Len returns the number of shards.
+func (ss *ShardedSelector[DataType, WeightType]) Len() int {
+	return len(ss.shards)
+}
+
+// Shard returns the Selector for shard i. A shard must only be used by one
+// goroutine at a time.
+func (ss *ShardedSelector[DataType, WeightType]) Shard(i int) *Selector[DataType, WeightType] {
+	return ss.shards[i]
+}
+
+// SelectN appends n DataType selected from the Selector's Options to dst and
+// returns the extended slice. The draws are split into one contiguous chunk
+// per shard and every shard fills its chunk on its own goroutine, so for the
+// same seed and number of shards the result is always the same. SelectN must
+// not be called while any shard is in use elsewhere.
+func (ss *ShardedSelector[DataType, WeightType]) SelectN(n int, dst []DataType) []DataType {
+	if n < 1 {
+		return dst
+	}
+
+	start := len(dst)
+	dst = append(dst, make([]DataType, n)...)
+	out := dst[start:]
+
+	chunk := (n + len(ss.shards) - 1) / len(ss.shards)
+	var wg sync.WaitGroup
+	for i, shard := range ss.shards {
+		lo := min(i*chunk, n)
+		hi := min(lo+chunk, n)
+		if lo == hi {
+			break
+		}
+
+		wg.Add(1)
+		go func(shard *Selector[DataType, WeightType], part []DataType) {
+			defer wg.Done()
+			for j := range part {
+				part[j] = shard.Select()
+			}
+		}(shard, out[lo:hi])
+	}
+	wg.Wait()
+
+	return dst
+}
+
+// splitmix64 returns the SplitMix64 finaliser of x, used to derive well
+// separated seeds from small consecutive integers.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,103 @@
+package weightedoption
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSelector_WithSource(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	picker, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	a := picker.WithSource(rand.NewPCG(1, 2)).SelectN(100, nil)
+	b := picker.WithSource(rand.NewPCG(1, 2)).SelectN(100, nil)
+	if !slices.Equal(a, b) {
+		t.Errorf("Selectors with equally seeded generators returned different draws: %v != %v", a, b)
+	}
+}
+
+func TestNewShardedSelector(t *testing.T) {
+	t.Parallel()
+
+	picker, err := NewSelector(NewOption('a', 1))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "explicit shard count", n: 3, want: 3},
+		{name: "default shard count", n: 0, want: NewShardedSelector(picker, -1, 0).Len()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := NewShardedSelector(picker, tt.n, 0).Len(); got != tt.want {
+				t.Errorf("NewShardedSelector() shards = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardedSelector_SelectN(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	picker, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	prefix := []int{-1}
+	a := NewShardedSelector(picker, 4, 42).SelectN(testIterations, prefix)
+	b := NewShardedSelector(picker, 4, 42).SelectN(testIterations, nil)
+
+	if len(a) != testIterations+1 || a[0] != -1 {
+		t.Fatalf("SelectN() did not append to dst: len = %d, first = %d", len(a), a[0])
+	}
+	if !slices.Equal(a[1:], b) {
+		t.Error("SelectN() with the same seed and shard count was not reproducible")
+	}
+
+	counts := make(map[int]int)
+	for _, c := range b {
+		counts[c]++
+	}
+	verifyFrequencyCounts(t, counts, options)
+
+	if got := NewShardedSelector(picker, 8, 42).SelectN(3, nil); len(got) != 3 {
+		t.Errorf("SelectN() with fewer draws than shards returned %d values, want 3", len(got))
+	}
+}
+
+func BenchmarkShardedSelectParallel(b *testing.B) {
+	for n := BMMinOptions; n <= BMMaxOptions; n *= 10 {
+		b.Run(fmt.Sprintf("size=%s", fmt1eN(n)), func(b *testing.B) {
+			options := mockOptions(n)
+			selector, err := NewSelector(options...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sharded := NewShardedSelector(selector, 0, 0)
+			var next atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				shard := sharded.Shard(int(next.Add(1)-1) % sharded.Len())
+				for pb.Next() {
+					_ = shard.Select()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package weightedoption
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+// HashKey returns a 64-bit hash of key salted with salt. It is the same in
+// every process and on every architecture, so it can be used to assign a key
+// to an Option without storing the assignment.
+func HashKey(salt, key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	// FNV-1a mixes its last bytes poorly, so finalise it
+	return splitmix64(h.Sum64())
+}
+
+// SelectByHash returns the DataType which hash deterministically maps to, with
+// the same probabilities as Select when hash is uniformly distributed. Like
+// Rendezvous, every Option scores the hash, scaled by its weight, and the
+// highest scoring Option is returned, which takes O(n) time for n Options.
+// Options are identified by their index in the Options the Selector was
+// created from, so the result only depends on the weights and not on their
+// scale or precision, and when one Option's weight changes or an Option is
+// added at the end a hash only moves to or from that Option.
+func (s Selector[DataType, WeightType]) SelectByHash(hash uint64) DataType {
+	return s.options[s.hashPosition(hash)]
+}
+
+// SelectIndexByHash maps hash to an Option like SelectByHash, but returns its
+// index in the Options the Selector was created from instead of its DataType.
+func (s Selector[DataType, WeightType]) SelectIndexByHash(hash uint64) int {
+	return s.indices[s.hashPosition(hash)]
+}
+
+// hashPosition returns the position of the Option hash maps to.
+func (s Selector[DataType, WeightType]) hashPosition(hash uint64) int {
+	best, bestScore := 0, math.Inf(-1)
+	for i := range s.options {
+		seed := splitmix64(uint64(s.indices[i]))
+		if score := rendezvousScore(float64(s.weight(i)), hash^seed); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// Exposure records a unit being assigned to an Option by a StickySelector.
+type Exposure[DataType any] struct {
+	Salt string
+	Unit string
+	// Index is the index of the Option in the Options the Selector was created
+	// from.
+	Index int
+	Data  DataType
+}
+
+// StickySelector assigns units, such as user IDs, to Options by hashing them
+// with a salt, so the same unit is always assigned the same Option without
+// storing any state, for example to bucket users into the variants of an A/B
+// experiment. See SelectByHash for how assignments change when weights do.
+type StickySelector[DataType any, WeightType WeightConstraint] struct {
+	selector   *Selector[DataType, WeightType]
+	salt       string
+	onExposure atomic.Pointer[func(Exposure[DataType])]
+}
+
+// NewStickySelector creates a new StickySelector which assigns units to the
+// Options of s, hashed with salt. Using a different salt for each experiment
+// keeps the assignments of different experiments independent.
+func NewStickySelector[DataType any, WeightType WeightConstraint](
+	s *Selector[DataType, WeightType],
+	salt string,
+) *StickySelector[DataType, WeightType] {
+	return &StickySelector[DataType, WeightType]{selector: s, salt: salt}
+}
+
+// OnExposure registers fn to be called with an Exposure each time Assign is
+// called. Passing nil removes the callback.
+func (ss *StickySelector[DataType, WeightType]) OnExposure(fn func(Exposure[DataType])) {
+	if fn == nil {
+		ss.onExposure.Store(nil)
+		return
+	}
+	ss.onExposure.Store(&fn)
+}
+
+// Assign returns the DataType unit is assigned to and reports the Exposure to
+// the OnExposure callback.
+func (ss *StickySelector[DataType, WeightType]) Assign(unit string) DataType {
+	pos := ss.selector.hashPosition(HashKey(ss.salt, unit))
+	data := ss.selector.options[pos]
+	if fn := ss.onExposure.Load(); fn != nil {
+		(*fn)(Exposure[DataType]{Salt: ss.salt, Unit: unit, Index: ss.selector.indices[pos], Data: data})
+	}
+	return data
+}
+
+// Peek returns the DataType unit is assigned to without reporting an Exposure.
+func (ss *StickySelector[DataType, WeightType]) Peek(unit string) DataType {
+	return ss.selector.SelectByHash(HashKey(ss.salt, unit))
+}
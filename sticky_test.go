@@ -0,0 +1,189 @@
+package weightedoption
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestHashKey(t *testing.T) {
+	t.Parallel()
+
+	if HashKey("salt", "user") != HashKey("salt", "user") {
+		t.Error("HashKey() is not deterministic")
+	}
+	if HashKey("salt", "user") == HashKey("other", "user") {
+		t.Error("HashKey() ignores the salt")
+	}
+	if HashKey("ab", "c") == HashKey("a", "bc") {
+		t.Error("HashKey() doesn't separate the salt from the key")
+	}
+}
+
+func TestSelector_SelectByHash(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption('a', 1), NewOption('b', 0), NewOption('c', 3))
+
+	counts := make(map[rune]int)
+	const n = 100_000
+	for i := range n {
+		h := HashKey("dist", strconv.Itoa(i))
+		data := s.SelectByHash(h)
+		if data != s.SelectByHash(h) {
+			t.Fatalf("SelectByHash(%d) is not deterministic", h)
+		}
+		if index := s.SelectIndexByHash(h); (index == 0) != (data == 'a') || index == 1 {
+			t.Fatalf("SelectIndexByHash(%d) = %d, which doesn't match %c", h, index, data)
+		}
+		counts[data]++
+	}
+
+	if got := float64(counts['a']) / n; got < 0.24 || got > 0.26 {
+		t.Errorf("SelectByHash() selected a with frequency %.3f, want 0.25", got)
+	}
+
+	single := mustSelector(t, NewOption('z', 1))
+	if got := single.SelectByHash(42); got != 'z' {
+		t.Errorf("SelectByHash() with one Option = %c, want z", got)
+	}
+}
+
+func TestSelector_SelectByHashStability(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []Option[rune, float64]
+		after  []Option[rune, float64]
+		// changed is the Option whose weight changed, if any
+		changed rune
+	}{
+		{
+			name:   "same shares at another scale",
+			before: []Option[rune, float64]{{Data: 'a', Weight: 9}, {Data: 'b', Weight: 1}},
+			after:  []Option[rune, float64]{{Data: 'a', Weight: 900}, {Data: 'b', Weight: 100}},
+		},
+		{
+			name:    "total crosses a power of two",
+			before:  []Option[rune, float64]{{Data: 'a', Weight: 32}, {Data: 'b', Weight: 32}},
+			after:   []Option[rune, float64]{{Data: 'a', Weight: 32}, {Data: 'b', Weight: 33}},
+			changed: 'b',
+		},
+		{
+			name:    "extra decimal digit",
+			before:  []Option[rune, float64]{{Data: 'a', Weight: 30}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 40}},
+			after:   []Option[rune, float64]{{Data: 'a', Weight: 30}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 40.5}},
+			changed: 'c',
+		},
+		{
+			name:    "Option added",
+			before:  []Option[rune, float64]{{Data: 'a', Weight: 50}, {Data: 'b', Weight: 50}},
+			after:   []Option[rune, float64]{{Data: 'a', Weight: 50}, {Data: 'b', Weight: 50}, {Data: 'c', Weight: 10}},
+			changed: 'c',
+		},
+		{
+			name:    "last grows across a power of two",
+			before:  []Option[rune, float64]{{Data: 'a', Weight: 30}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 3}},
+			after:   []Option[rune, float64]{{Data: 'a', Weight: 30}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 70}},
+			changed: 'c',
+		},
+		{
+			name:    "first shrinks",
+			before:  []Option[rune, float64]{{Data: 'a', Weight: 50}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 20}},
+			after:   []Option[rune, float64]{{Data: 'a', Weight: 10}, {Data: 'b', Weight: 30}, {Data: 'c', Weight: 20}},
+			changed: 'a',
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			before, after := mustSelector(t, tt.before...), mustSelector(t, tt.after...)
+
+			const n = 100_000
+			moved := 0
+			for i := range n {
+				h := HashKey("stability", strconv.Itoa(i))
+				from, to := before.SelectByHash(h), after.SelectByHash(h)
+				if from == to {
+					continue
+				}
+				moved++
+				if from != tt.changed && to != tt.changed {
+					t.Fatalf("hash %d moved from %c to %c, neither of which changed", h, from, to)
+				}
+			}
+
+			// Only the change in the changed Option's share moves
+			if got, limit := float64(moved)/n, shareChange(tt.before, tt.after, tt.changed)+0.005; got > limit {
+				t.Errorf("%.4f of hashes moved, want at most %.4f", got, limit)
+			}
+		})
+	}
+}
+
+// shareChange returns how much the share of the Option with the provided Data
+// changed between two sets of Options.
+func shareChange(before, after []Option[rune, float64], data rune) float64 {
+	share := func(opts []Option[rune, float64]) float64 {
+		var weight, total float64
+		for _, opt := range opts {
+			total += opt.Weight
+			if opt.Data == data {
+				weight = opt.Weight
+			}
+		}
+		return weight / total
+	}
+	return math.Abs(share(after) - share(before))
+}
+
+func TestStickySelector(t *testing.T) {
+	t.Parallel()
+
+	s := mustSelector(t, NewOption("control", 1), NewOption("treatment", 1))
+	ss := NewStickySelector(s, "exp-1")
+
+	var exposures []Exposure[string]
+	ss.OnExposure(func(e Exposure[string]) {
+		exposures = append(exposures, e)
+	})
+
+	got := ss.Assign("user-1")
+	if again := ss.Assign("user-1"); again != got {
+		t.Errorf("Assign() = %s then %s, want the same variant", got, again)
+	}
+	if peek := ss.Peek("user-1"); peek != got {
+		t.Errorf("Peek() = %s, want %s", peek, got)
+	}
+
+	if len(exposures) != 2 {
+		t.Fatalf("got %d exposures, want 2 as Peek doesn't report one", len(exposures))
+	}
+	want := Exposure[string]{Salt: "exp-1", Unit: "user-1", Index: 0, Data: got}
+	if got == "treatment" {
+		want.Index = 1
+	}
+	if exposures[0] != want {
+		t.Errorf("exposure = %+v, want %+v", exposures[0], want)
+	}
+
+	ss.OnExposure(nil)
+	ss.Assign("user-2")
+	if len(exposures) != 2 {
+		t.Error("Assign() reported an exposure after the callback was removed")
+	}
+
+	// Different salts give independent assignments
+	other := NewStickySelector(s, "exp-2")
+	same := 0
+	for i := range 1000 {
+		unit := strconv.Itoa(i)
+		if ss.Peek(unit) == other.Peek(unit) {
+			same++
+		}
+	}
+	if same < 400 || same > 600 {
+		t.Errorf("%d of 1000 units had the same variant in both experiments, want about 500", same)
+	}
+}
@@ -0,0 +1,125 @@
+package weightedoption
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+)
+
+// ErrIndexOutOfRange is returned for an index which isn't in the Options a
+// StockSelector or RoundRobin was created from.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// StockSelector selects Options with finite stock, like tickets in a raffle or
+// items in a limited loot pool. Each Option is selected with a probability
+// proportional to its remaining stock, and selecting it takes one from its
+// stock, so exhausted Options leave the pool until they are restocked. It is
+// safe for concurrent use.
+type StockSelector[DataType any] struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	options []DataType
+	stock   *fenwick
+}
+
+// NewStockSelector creates a new StockSelector where the Weight of each Option
+// is its initial stock. Options with no stock are kept so they can be
+// restocked. If the total stock exceeds the max integer value for this
+// system's architecture ErrTotalWeightOverflow is returned.
+func NewStockSelector[DataType any](opts ...Option[DataType, uint]) (*StockSelector[DataType], error) {
+	return NewStockSelectorWith(opts)
+}
+
+// NewStockSelectorWith creates a new StockSelector like NewStockSelector,
+// drawing from the source set by WithSource, if any, instead of the global
+// random number generator. WithSource is the only SelectorOption which applies
+// to stock; if any other is passed ErrUnsupportedOption is returned.
+func NewStockSelectorWith[DataType any](
+	opts []Option[DataType, uint],
+	cfg ...SelectorOption,
+) (*StockSelector[DataType], error) {
+	c := newSelectorConfig(cfg)
+	if c.strict || c.precision >= 0 || c.mergeDuplicates || c.algorithm != AlgorithmBinarySearch {
+		return nil, ErrUnsupportedOption
+	}
+	options := make([]DataType, len(opts))
+	stock := make([]uint, len(opts))
+	var total uint
+	for i, opt := range opts {
+		if (math.MaxInt - total) < opt.Weight {
+			return nil, ErrTotalWeightOverflow
+		}
+		total += opt.Weight
+		options[i] = opt.Data
+		stock[i] = opt.Weight
+	}
+
+	s := &StockSelector[DataType]{
+		options: options,
+		stock:   newFenwick(stock),
+	}
+	if c.source != nil {
+		s.rng = rand.New(c.source)
+	}
+	return s, nil
+}
+
+// Select returns a single DataType selected by remaining stock and takes one
+// from its stock. If no stock is left ErrNoValidOptions is returned.
+func (s *StockSelector[DataType]) Select() (DataType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stock.total == 0 {
+		var zero DataType
+		return zero, ErrNoValidOptions
+	}
+
+	uintN := rand.UintN
+	if s.rng != nil {
+		uintN = s.rng.UintN
+	}
+	i := s.stock.find(uintN(s.stock.total))
+	s.stock.sub(i, 1)
+	return s.options[i], nil
+}
+
+// Restock adds n to the stock of the Option at index i in the Options the
+// StockSelector was created from. If there is no such Option
+// ErrIndexOutOfRange is returned, and if the total stock would exceed the max
+// integer value for this system's architecture ErrTotalWeightOverflow is
+// returned. In both cases the stock is unchanged.
+func (s *StockSelector[DataType]) Restock(i int, n uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i < 0 || i >= len(s.options) {
+		return ErrIndexOutOfRange
+	}
+	if (math.MaxInt - s.stock.total) < n {
+		return ErrTotalWeightOverflow
+	}
+	s.stock.add(i, n)
+	return nil
+}
+
+// Remaining returns the stock left of the Option at index i in the Options the
+// StockSelector was created from. If there is no such Option
+// ErrIndexOutOfRange is returned.
+func (s *StockSelector[DataType]) Remaining(i int) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i < 0 || i >= len(s.options) {
+		return 0, ErrIndexOutOfRange
+	}
+	return s.stock.get(i), nil
+}
+
+// Total returns the total stock left.
+func (s *StockSelector[DataType]) Total() uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stock.total
+}
@@ -0,0 +1,171 @@
+package weightedoption
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+func TestNewStockSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cs        []Option[rune, uint]
+		wantTotal uint
+		wantErr   error
+	}{
+		{name: "no options", cs: nil},
+		{name: "nominal case", cs: []Option[rune, uint]{{Data: 'a', Weight: 2}, {Data: 'b', Weight: 0}, {Data: 'c', Weight: 3}}, wantTotal: 5},
+		{name: "overflow", cs: []Option[rune, uint]{{Data: 'a', Weight: math.MaxInt}, {Data: 'b', Weight: 1}}, wantErr: ErrTotalWeightOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := NewStockSelector(tt.cs...)
+			if err != tt.wantErr {
+				t.Fatalf("NewStockSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.Total() != tt.wantTotal {
+				t.Errorf("Total() = %d, want %d", s.Total(), tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestStockSelector_Select(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewStockSelector(NewOption('a', uint(2)), NewOption('b', uint(0)), NewOption('c', uint(3)))
+	if err != nil {
+		t.Fatal("Failed to create StockSelector:", err)
+	}
+
+	counts := make(map[rune]int)
+	for i := 0; i < 5; i++ {
+		data, err := s.Select()
+		if err != nil {
+			t.Fatal("Select() error:", err)
+		}
+		counts[data]++
+	}
+	if counts['a'] != 2 || counts['b'] != 0 || counts['c'] != 3 {
+		t.Errorf("counts = %v, want 2 a, 0 b and 3 c", counts)
+	}
+	if _, err := s.Select(); err != ErrNoValidOptions {
+		t.Errorf("Select() on an empty pool error = %v, wantErr %v", err, ErrNoValidOptions)
+	}
+
+	if err := s.Restock(1, 1); err != nil {
+		t.Fatal("Restock() error:", err)
+	}
+	if got, err := s.Remaining(1); err != nil || got != 1 {
+		t.Errorf("Remaining(1) = %d, %v, want 1, nil", got, err)
+	}
+	if data, err := s.Select(); err != nil || data != 'b' {
+		t.Errorf("Select() after Restock() = %c, %v, want b, nil", data, err)
+	}
+	if err := s.Restock(0, math.MaxInt); err != nil {
+		t.Fatal("Restock() error:", err)
+	}
+	if err := s.Restock(0, 1); err != ErrTotalWeightOverflow {
+		t.Errorf("Restock() error = %v, wantErr %v", err, ErrTotalWeightOverflow)
+	}
+}
+
+func TestStockSelector_RestockOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewStockSelector(NewOption('a', uint(1)))
+	if err != nil {
+		t.Fatal("Failed to create StockSelector:", err)
+	}
+
+	for _, i := range []int{-1, 1, 5} {
+		if err := s.Restock(i, 3); err != ErrIndexOutOfRange {
+			t.Errorf("Restock(%d, 3) error = %v, wantErr %v", i, err, ErrIndexOutOfRange)
+		}
+		if _, err := s.Remaining(i); err != ErrIndexOutOfRange {
+			t.Errorf("Remaining(%d) error = %v, wantErr %v", i, err, ErrIndexOutOfRange)
+		}
+	}
+	if got := s.Total(); got != 1 {
+		t.Errorf("Total() after out of range Restock() = %d, want 1", got)
+	}
+	if data, err := s.Select(); err != nil || data != 'a' {
+		t.Errorf("Select() = %c, %v, want a, nil", data, err)
+	}
+}
+
+func TestNewStockSelectorWith(t *testing.T) {
+	t.Parallel()
+
+	draws := func() []rune {
+		s, err := NewStockSelectorWith(
+			[]Option[rune, uint]{NewOption('a', uint(10)), NewOption('b', uint(10)), NewOption('c', uint(10))},
+			WithSource(rand.NewPCG(13, 14)),
+		)
+		if err != nil {
+			t.Fatal("Failed to create StockSelector:", err)
+		}
+		var got []rune
+		for range 30 {
+			data, err := s.Select()
+			if err != nil {
+				t.Fatal("Select() error:", err)
+			}
+			got = append(got, data)
+		}
+		return got
+	}
+
+	if a, b := draws(), draws(); string(a) != string(b) {
+		t.Errorf("draws with the same source differ: %c and %c", a, b)
+	}
+
+	unsupported := []SelectorOption{WithStrict(), WithPrecision(2), WithMergeDuplicates(), WithAlgorithm(AlgorithmAlias)}
+	for _, opt := range unsupported {
+		if _, err := NewStockSelectorWith([]Option[rune, uint]{NewOption('a', uint(1))}, opt); err != ErrUnsupportedOption {
+			t.Errorf("NewStockSelectorWith() error = %v, wantErr %v", err, ErrUnsupportedOption)
+		}
+	}
+}
+
+func TestStockSelector_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	const stock = 10_000
+	s, err := NewStockSelector(NewOption(0, uint(stock/2)), NewOption(1, uint(stock/2)))
+	if err != nil {
+		t.Fatal("Failed to create StockSelector:", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		drawn  int
+		counts [2]int
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				data, err := s.Select()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				drawn++
+				counts[data]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if drawn != stock || counts[0] != stock/2 || counts[1] != stock/2 {
+		t.Errorf("drew %d with counts %v, want %d with %d each", drawn, counts, stock, stock/2)
+	}
+}
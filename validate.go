@@ -0,0 +1,274 @@
+package weightedoption
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Reason describes why an Option failed validation.
+type Reason int
+
+const (
+	// ReasonNegative is used when an Option's Weight is less than 0.
+	ReasonNegative Reason = iota + 1
+	// ReasonZero is used when an Option's Weight is 0.
+	ReasonZero
+	// ReasonNaN is used when an Option's Weight is NaN.
+	ReasonNaN
+	// ReasonInf is used when an Option's Weight is positive or negative infinity.
+	ReasonInf
+	// ReasonOverflow is used when an Option's Weight, once scaled to an integer,
+	// exceeds the max integer value for this system's architecture.
+	ReasonOverflow
+	// ReasonTotalOverflow is used for the Option at which the running total
+	// weight first exceeds the max integer value for this system's architecture.
+	ReasonTotalOverflow
+	// ReasonDuplicate is used when an Option's Data equals the Data of an
+	// earlier Option.
+	ReasonDuplicate
+)
+
+var reasonNames = map[Reason]string{
+	ReasonNegative:      "negative",
+	ReasonZero:          "zero",
+	ReasonNaN:           "NaN",
+	ReasonInf:           "Inf",
+	ReasonOverflow:      "overflow",
+	ReasonTotalOverflow: "total overflow",
+	ReasonDuplicate:     "duplicate",
+}
+
+// String returns the name of the Reason.
+func (r Reason) String() string {
+	if name, ok := reasonNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Reason(%d)", int(r))
+}
+
+// Issue describes a single Option which failed validation.
+type Issue struct {
+	// Index is the position of the Option in the provided Options.
+	Index  int
+	Data   any
+	Weight any
+	Reason Reason
+}
+
+// String returns a description of the Issue.
+func (i Issue) String() string {
+	return fmt.Sprintf("option %d: %s weight: data=%v, weight=%v", i.Index, i.Reason, i.Data, i.Weight)
+}
+
+// ValidationError is returned by Validate and NewStrictSelector and lists
+// every Option which failed validation. It matches ErrNoValidOptions,
+// ErrSingleWeightOverflow and ErrTotalWeightOverflow with errors.Is when the
+// same conditions would have caused NewSelector to return them.
+type ValidationError struct {
+	Issues    []Issue
+	sentinels []error
+}
+
+// Error returns a description of every Issue.
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid options")
+	for _, sentinel := range e.sentinels {
+		b.WriteString(": ")
+		b.WriteString(sentinel.Error())
+	}
+	for _, issue := range e.Issues {
+		b.WriteString("; ")
+		b.WriteString(issue.String())
+	}
+	return b.String()
+}
+
+// Unwrap returns the sentinel errors matched by the ValidationError.
+func (e *ValidationError) Unwrap() []error {
+	return e.sentinels
+}
+
+// Validate checks every provided Option and returns a *ValidationError
+// listing each one with a negative, zero, NaN, infinite, overflowing or
+// duplicate weight, or nil if there are none. Duplicates are only detected
+// when Data is comparable.
+func Validate[DataType any, WeightType WeightConstraint](
+	opts ...Option[DataType, WeightType],
+) error {
+	return validate(newSelectorConfig(nil), opts)
+}
+
+// validate implements Validate, scaling float weights with the configured
+// precision and skipping duplicates when they will be merged.
+func validate[DataType any, WeightType WeightConstraint](
+	c selectorConfig,
+	opts []Option[DataType, WeightType],
+) error {
+	verr := &ValidationError{}
+	var valid []int
+
+	for i, opt := range opts {
+		if reason := weightReason(opt.Weight); reason != 0 {
+			addIssue(verr, i, opt, reason)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) == 0 {
+		verr.sentinels = append(verr.sentinels, ErrNoValidOptions)
+	} else {
+		validateTotals(verr, opts, valid, c.precision)
+	}
+
+	if !c.mergeDuplicates {
+		validateDuplicates(verr, opts)
+	}
+
+	if len(verr.Issues) == 0 && len(verr.sentinels) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(verr.Issues, func(a, b Issue) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	return verr
+}
+
+// NewStrictSelector creates a new Selector for selecting provided Options, like
+// NewSelector, but returns a *ValidationError from Validate instead of silently
+// ignoring Options which can never be selected. It is the same as calling
+// NewSelectorWith with WithStrict.
+func NewStrictSelector[DataType any, WeightType WeightConstraint](
+	opts ...Option[DataType, WeightType],
+) (*Selector[DataType, WeightType], error) {
+	return NewSelectorWith(opts, WithStrict())
+}
+
+// addIssue records an Issue for the Option at index.
+func addIssue[DataType any, WeightType WeightConstraint](
+	verr *ValidationError,
+	index int,
+	opt Option[DataType, WeightType],
+	reason Reason,
+) {
+	verr.Issues = append(verr.Issues, Issue{Index: index, Data: opt.Data, Weight: opt.Weight, Reason: reason})
+}
+
+// weightReason returns the Reason a weight is invalid on its own, or 0 if it
+// is valid.
+func weightReason[WeightType WeightConstraint](weight WeightType) Reason {
+	switch {
+	// NaN is the only value which doesn't equal itself
+	case weight != weight:
+		return ReasonNaN
+	case math.IsInf(float64(weight), 0):
+		return ReasonInf
+	case weight < 0:
+		return ReasonNegative
+	case weight == 0:
+		return ReasonZero
+	}
+	return 0
+}
+
+// validateTotals scales the valid Options' weights the same way NewSelector
+// does and records every overflow, along with any weight a fixed precision
+// rounds down to 0.
+func validateTotals[DataType any, WeightType WeightConstraint](
+	verr *ValidationError,
+	opts []Option[DataType, WeightType],
+	valid []int,
+	precision int,
+) {
+	scale := 1.0
+	if isFloat[WeightType]() {
+		filtered := make([]Option[DataType, WeightType], len(valid))
+		for j, i := range valid {
+			filtered[j] = opts[i]
+		}
+		// Every weight is finite, so this can't fail
+		maxDigits, _ := maxFractionalDigits(filtered)
+		if precision >= 0 {
+			maxDigits = precision
+		}
+		scale = math.Pow(decimalBase, float64(maxDigits))
+	}
+
+	var totalWeight uint
+	totalOverflowed := false
+	for _, i := range valid {
+		var weight uint
+		if isFloat[WeightType]() {
+			scaled := math.Round(float64(opts[i].Weight) * scale)
+			if scaled >= math.MaxInt {
+				addIssue(verr, i, opts[i], ReasonOverflow)
+				continue
+			}
+			if scaled == 0 {
+				addIssue(verr, i, opts[i], ReasonZero)
+				continue
+			}
+			weight = uint(scaled)
+		} else {
+			if uint64(opts[i].Weight) > math.MaxInt {
+				addIssue(verr, i, opts[i], ReasonOverflow)
+				continue
+			}
+			weight = uint(opts[i].Weight)
+		}
+
+		if totalOverflowed {
+			continue
+		}
+		if (math.MaxInt - totalWeight) < weight {
+			addIssue(verr, i, opts[i], ReasonTotalOverflow)
+			verr.sentinels = append(verr.sentinels, ErrTotalWeightOverflow)
+			totalOverflowed = true
+			continue
+		}
+		totalWeight += weight
+	}
+
+	for _, issue := range verr.Issues {
+		if issue.Reason == ReasonOverflow {
+			verr.sentinels = append(verr.sentinels, ErrSingleWeightOverflow)
+			return
+		}
+	}
+
+	if totalWeight == 0 && !totalOverflowed {
+		verr.sentinels = append(verr.sentinels, ErrNoValidOptions)
+	}
+}
+
+// validateDuplicates records every Option whose Data equals the Data of an
+// earlier Option, skipping Data which isn't comparable.
+func validateDuplicates[DataType any, WeightType WeightConstraint](
+	verr *ValidationError,
+	opts []Option[DataType, WeightType],
+) {
+	seen := make(map[any]struct{}, len(opts))
+	for i, opt := range opts {
+		if !isComparable(opt.Data) {
+			continue
+		}
+
+		if _, ok := seen[opt.Data]; ok {
+			addIssue(verr, i, opt, ReasonDuplicate)
+			continue
+		}
+		seen[opt.Data] = struct{}{}
+	}
+}
+
+// isComparable reports whether data can be used as a map key without panicking.
+func isComparable(data any) bool {
+	v := reflect.ValueOf(data)
+	return v.IsValid() && v.Comparable()
+}
@@ -0,0 +1,173 @@
+package weightedoption
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cs          []Option[rune, float64]
+		wantReasons []Reason
+		wantIndexes []int
+		wantIs      []error
+	}{
+		{
+			name: "valid options",
+			cs:   []Option[rune, float64]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 0.5}},
+		},
+		{
+			name:   "no options",
+			cs:     []Option[rune, float64]{},
+			wantIs: []error{ErrNoValidOptions},
+		},
+		{
+			name: "every invalid weight",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: -1},
+				{Data: 'b', Weight: 0},
+				{Data: 'c', Weight: math.NaN()},
+				{Data: 'd', Weight: math.Inf(1)},
+				{Data: 'e', Weight: math.Inf(-1)},
+			},
+			wantReasons: []Reason{ReasonNegative, ReasonZero, ReasonNaN, ReasonInf, ReasonInf},
+			wantIndexes: []int{0, 1, 2, 3, 4},
+			wantIs:      []error{ErrNoValidOptions},
+		},
+		{
+			name: "some invalid weights",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1},
+				{Data: 'b', Weight: -1},
+				{Data: 'c', Weight: 2},
+			},
+			wantReasons: []Reason{ReasonNegative},
+			wantIndexes: []int{1},
+		},
+		{
+			name: "duplicate data",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1},
+				{Data: 'b', Weight: 1},
+				{Data: 'a', Weight: 0},
+			},
+			wantReasons: []Reason{ReasonZero, ReasonDuplicate},
+			wantIndexes: []int{2, 2},
+		},
+		{
+			name: "single weight overflow",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1e300},
+				{Data: 'b', Weight: 1},
+			},
+			wantReasons: []Reason{ReasonOverflow},
+			wantIndexes: []int{0},
+			wantIs:      []error{ErrSingleWeightOverflow},
+		},
+		{
+			name: "total weight overflow",
+			cs: []Option[rune, float64]{
+				{Data: 'a', Weight: 1},
+				{Data: 'b', Weight: 6e18},
+				{Data: 'c', Weight: 6e18},
+			},
+			wantReasons: []Reason{ReasonTotalOverflow},
+			wantIndexes: []int{2},
+			wantIs:      []error{ErrTotalWeightOverflow},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := Validate(tt.cs...)
+			if tt.wantReasons == nil && tt.wantIs == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+
+			reasons := make([]Reason, len(verr.Issues))
+			indexes := make([]int, len(verr.Issues))
+			for i, issue := range verr.Issues {
+				reasons[i] = issue.Reason
+				indexes[i] = issue.Index
+			}
+			if !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("Validate() reasons = %v, want %v", reasons, tt.wantReasons)
+			}
+			if !slices.Equal(indexes, tt.wantIndexes) {
+				t.Errorf("Validate() indexes = %v, want %v", indexes, tt.wantIndexes)
+			}
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = false, want true", err, target)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUncomparableData(t *testing.T) {
+	t.Parallel()
+
+	err := Validate(NewOption[any](func() {}, 1), NewOption[any]([]int{1}, 1))
+	if err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestNewStrictSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cs      []Option[rune, int]
+		wantErr bool
+		wantIs  error
+	}{
+		{
+			name: "nominal case",
+			cs:   []Option[rune, int]{{Data: 'a', Weight: 1}, {Data: 'b', Weight: 2}},
+		},
+		{
+			name:    "one valid option and one invalid option with negative weight",
+			cs:      []Option[rune, int]{{Data: 'a', Weight: 3}, {Data: 'b', Weight: -2}},
+			wantErr: true,
+		},
+		{
+			name:    "weight overflow",
+			cs:      []Option[rune, int]{{Data: 'a', Weight: math.MaxInt/2 + 1}, {Data: 'b', Weight: math.MaxInt/2 + 1}},
+			wantErr: true,
+			wantIs:  ErrTotalWeightOverflow,
+		},
+		{
+			name:    "no options with weight greater than 0",
+			cs:      []Option[rune, int]{{Data: 'a', Weight: 0}},
+			wantErr: true,
+			wantIs:  ErrNoValidOptions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewStrictSelector(tt.cs...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStrictSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantIs)
+			}
+		})
+	}
+}
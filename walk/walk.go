@@ -0,0 +1,296 @@
+// Package walk generates weighted random walks over graphs, for example to
+// carve procedural dungeon paths or explore a recommendation graph, with
+// optional restarts and node2vec return and in-out biasing.
+package walk
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+// ErrInvalidConfig is returned by NewWalker for a restart probability outside
+// [0, 1) or a return or in-out parameter which isn't positive and finite.
+var ErrInvalidConfig = errors.New("invalid walk configuration")
+
+// Graph is an adjacency list mapping each node to its outgoing edges, where an
+// edge's Data is the node it leads to and its Weight how likely it is to be
+// taken. Edges with a negative, zero, NaN or infinite weight are ignored and
+// edges leading to the same node have their weights summed.
+type Graph[Node comparable, WeightType weightedoption.WeightConstraint] map[Node][]weightedoption.Option[Node, WeightType]
+
+// WalkerOption configures a Walker created by NewWalker.
+type WalkerOption func(*walkerConfig)
+
+type walkerConfig struct {
+	restart float64
+	p, q    float64
+	source  rand.Source
+}
+
+// WithRestart makes each step of a walk jump back to its start node with
+// probability p instead of taking an edge, as in random walks with restart.
+func WithRestart(p float64) WalkerOption {
+	return func(c *walkerConfig) {
+		c.restart = p
+	}
+}
+
+// WithBias biases each step by the node the walk came from, as in node2vec.
+// Returning to the previous node has its edge weight divided by p, and moving
+// to a node which isn't a neighbour of the previous node has its edge weight
+// divided by q, so a low p keeps walks local and a low q sends them outwards.
+// Both default to 1, which leaves walks unbiased.
+func WithBias(p, q float64) WalkerOption {
+	return func(c *walkerConfig) {
+		c.p = p
+		c.q = q
+	}
+}
+
+// WithSource makes the Walker draw from src instead of the global random
+// number generator, so a seeded src makes walks reproducible. As src is
+// usually not safe for concurrent use, neither is the Walker.
+func WithSource(src rand.Source) WalkerOption {
+	return func(c *walkerConfig) {
+		c.source = src
+	}
+}
+
+// maxBiasRatio is the largest ratio between the biases a step may apply for
+// which node2vec biasing is applied by rejection sampling. Rejection then
+// takes at most this many draws on average; beyond it the step is drawn
+// exactly from the biased weights of the current node's edges instead.
+const maxBiasRatio = 16
+
+// edge is an edge of a Walker's graph with its merged weight.
+type edge[Node comparable] struct {
+	to     Node
+	weight float64
+}
+
+// Walker generates weighted random walks over a Graph. Each node's next hop is
+// selected from its edges by an alias table in O(1) time, and node2vec biasing
+// is applied by rejection sampling so it doesn't need a table per edge. When
+// the biases a step may apply differ too much for rejection to be quick, the
+// step is drawn exactly in time proportional to the number of edges.
+type Walker[Node comparable, WeightType weightedoption.WeightConstraint] struct {
+	selectors map[Node]*weightedoption.Selector[Node, WeightType]
+	edges     map[Node][]edge[Node]
+	neighbors map[Node]map[Node]struct{}
+	cfg       walkerConfig
+	rng       *rand.Rand
+	biased    bool
+}
+
+// NewWalker creates a new Walker over the provided Graph, configured by the
+// provided WalkerOptions. Nodes without valid edges are dead ends which stop a
+// walk. If no node has a valid edge weightedoption.ErrNoValidOptions is
+// returned.
+func NewWalker[Node comparable, WeightType weightedoption.WeightConstraint](
+	g Graph[Node, WeightType],
+	cfg ...WalkerOption,
+) (*Walker[Node, WeightType], error) {
+	c := walkerConfig{p: 1, q: 1}
+	for _, opt := range cfg {
+		opt(&c)
+	}
+	if c.restart < 0 || c.restart >= 1 || math.IsNaN(c.restart) {
+		return nil, fmt.Errorf("%w: restart probability %v is not in [0, 1)", ErrInvalidConfig, c.restart)
+	}
+	for _, v := range []float64{c.p, c.q} {
+		if v <= 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("%w: bias %v is not positive and finite", ErrInvalidConfig, v)
+		}
+	}
+
+	w := &Walker[Node, WeightType]{
+		selectors: make(map[Node]*weightedoption.Selector[Node, WeightType], len(g)),
+		edges:     make(map[Node][]edge[Node], len(g)),
+		neighbors: make(map[Node]map[Node]struct{}, len(g)),
+		cfg:       c,
+		biased:    c.p != 1 || c.q != 1,
+	}
+	selectorCfg := []weightedoption.SelectorOption{
+		weightedoption.WithMergeDuplicates(),
+		weightedoption.WithAlgorithm(weightedoption.AlgorithmAlias),
+	}
+	if c.source != nil {
+		w.rng = rand.New(c.source)
+		selectorCfg = append(selectorCfg, weightedoption.WithSource(c.source))
+	}
+	for node, edges := range g {
+		s, err := weightedoption.NewSelectorWith(edges, selectorCfg...)
+		if errors.Is(err, weightedoption.ErrNoValidOptions) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		w.selectors[node] = s
+
+		neighbors := make(map[Node]struct{}, len(edges))
+		for _, i := range s.InputIndices() {
+			neighbors[edges[i].Data] = struct{}{}
+		}
+		w.neighbors[node] = neighbors
+		if w.biased {
+			w.edges[node] = mergeEdges(edges, s.InputIndices())
+		}
+	}
+
+	if len(w.selectors) == 0 {
+		return nil, weightedoption.ErrNoValidOptions
+	}
+	return w, nil
+}
+
+// Walk returns a sequence of the nodes visited by a walk from start, beginning
+// with start itself. The walk stops when it reaches a dead end, so unless
+// every node has an edge the caller should limit it.
+func (w *Walker[Node, WeightType]) Walk(start Node) iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		if !yield(start) {
+			return
+		}
+
+		current, previous, hasPrevious := start, start, false
+		for {
+			if w.cfg.restart > 0 && w.float64() < w.cfg.restart {
+				current, hasPrevious = start, false
+				if !yield(current) {
+					return
+				}
+				continue
+			}
+
+			s, ok := w.selectors[current]
+			if !ok {
+				return
+			}
+			var next Node
+			if hasPrevious && w.biased {
+				next = w.biasedStep(s, current, previous)
+			} else {
+				next = s.Select()
+			}
+
+			current, previous, hasPrevious = next, current, true
+			if !yield(current) {
+				return
+			}
+		}
+	}
+}
+
+// Path returns the first length nodes of a walk from start, or fewer if it
+// reaches a dead end.
+func (w *Walker[Node, WeightType]) Path(start Node, length int) []Node {
+	path := make([]Node, 0, max(length, 0))
+	if length < 1 {
+		return path
+	}
+	for node := range w.Walk(start) {
+		path = append(path, node)
+		if len(path) == length {
+			break
+		}
+	}
+	return slices.Clip(path)
+}
+
+// mergeEdges returns the edges at the provided indexes, which are those the
+// Selector of their node kept, with the weights of the valid edges leading to
+// the same node summed as the Selector does.
+func mergeEdges[Node comparable, WeightType weightedoption.WeightConstraint](
+	edges []weightedoption.Option[Node, WeightType],
+	indices []int,
+) []edge[Node] {
+	weights := make(map[Node]float64, len(indices))
+	for _, e := range edges {
+		if w := float64(e.Weight); w > 0 && !math.IsInf(w, 0) {
+			weights[e.Data] += w
+		}
+	}
+	merged := make([]edge[Node], len(indices))
+	for i, index := range indices {
+		to := edges[index].Data
+		merged[i] = edge[Node]{to: to, weight: weights[to]}
+	}
+	return merged
+}
+
+// biasedStep applies node2vec biasing to a step from current, whose Selector
+// is s, having come from previous. The biases the step may apply are bounded
+// without looking at every edge: returning is only possible if previous is a
+// neighbour of current. If they differ by at most maxBiasRatio, candidates
+// are drawn from s and each accepted with probability proportional to its
+// bias, so the accepted node is selected with probability proportional to its
+// edge weight multiplied by its bias. Otherwise the step is drawn exactly.
+func (w *Walker[Node, WeightType]) biasedStep(
+	s *weightedoption.Selector[Node, WeightType],
+	current, previous Node,
+) Node {
+	lowest, highest := min(1, 1/w.cfg.q), max(1, 1/w.cfg.q)
+	if _, ok := w.neighbors[current][previous]; ok {
+		lowest, highest = min(lowest, 1/w.cfg.p), max(highest, 1/w.cfg.p)
+	}
+	if highest/lowest > maxBiasRatio {
+		return w.exactStep(current, previous)
+	}
+
+	for {
+		candidate := s.Select()
+		if w.float64()*highest < w.bias(previous, candidate) {
+			return candidate
+		}
+	}
+}
+
+// exactStep draws a step from current, having come from previous, from the
+// weights of its edges multiplied by their biases.
+func (w *Walker[Node, WeightType]) exactStep(current, previous Node) Node {
+	edges := w.edges[current]
+	biased := make([]float64, len(edges))
+	var total float64
+	for i, e := range edges {
+		biased[i] = e.weight * w.bias(previous, e.to)
+		total += biased[i]
+	}
+
+	r := w.float64() * total
+	for i, weight := range biased {
+		if r < weight {
+			return edges[i].to
+		}
+		r -= weight
+	}
+	// Rounding can leave r just past the last weight
+	return edges[len(edges)-1].to
+}
+
+// bias returns the node2vec factor for stepping to next having come from
+// previous.
+func (w *Walker[Node, WeightType]) bias(previous, next Node) float64 {
+	if next == previous {
+		return 1 / w.cfg.p
+	}
+	if _, ok := w.neighbors[previous][next]; ok {
+		return 1
+	}
+	return 1 / w.cfg.q
+}
+
+// float64 returns a random number in [0.0, 1.0) from the Walker's random number
+// generator, or from the global one if none was set.
+func (w *Walker[Node, WeightType]) float64() float64 {
+	if w.rng != nil {
+		return w.rng.Float64()
+	}
+	return rand.Float64()
+}
@@ -0,0 +1,218 @@
+package walk
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/eljamo/weightedoption/v3"
+)
+
+type testGraph = Graph[string, int]
+
+func edges(targets ...string) []weightedoption.Option[string, int] {
+	opts := make([]weightedoption.Option[string, int], len(targets))
+	for i, target := range targets {
+		opts[i] = weightedoption.NewOption(target, 1)
+	}
+	return opts
+}
+
+func mustWalker(t *testing.T, g testGraph, cfg ...WalkerOption) *Walker[string, int] {
+	t.Helper()
+	w, err := NewWalker(g, cfg...)
+	if err != nil {
+		t.Fatal("Failed to create Walker:", err)
+	}
+	return w
+}
+
+func TestNewWalker(t *testing.T) {
+	t.Parallel()
+
+	g := testGraph{"a": edges("b")}
+	tests := []struct {
+		name    string
+		g       testGraph
+		cfg     []WalkerOption
+		wantErr error
+	}{
+		{name: "valid", g: g},
+		{name: "no edges", g: testGraph{"a": {{Data: "b", Weight: 0}}}, wantErr: weightedoption.ErrNoValidOptions},
+		{name: "negative restart", g: g, cfg: []WalkerOption{WithRestart(-0.1)}, wantErr: ErrInvalidConfig},
+		{name: "certain restart", g: g, cfg: []WalkerOption{WithRestart(1)}, wantErr: ErrInvalidConfig},
+		{name: "zero p", g: g, cfg: []WalkerOption{WithBias(0, 1)}, wantErr: ErrInvalidConfig},
+		{name: "negative q", g: g, cfg: []WalkerOption{WithBias(1, -1)}, wantErr: ErrInvalidConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := NewWalker(tt.g, tt.cfg...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewWalker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWalker_Path(t *testing.T) {
+	t.Parallel()
+
+	w := mustWalker(t, testGraph{"a": edges("b"), "b": edges("c"), "z": edges("z")})
+	tests := []struct {
+		name   string
+		start  string
+		length int
+		want   []string
+	}{
+		{name: "dead end", start: "a", length: 10, want: []string{"a", "b", "c"}},
+		{name: "limited", start: "a", length: 2, want: []string{"a", "b"}},
+		{name: "zero length", start: "a", length: 0, want: []string{}},
+		{name: "unknown start", start: "x", length: 3, want: []string{"x"}},
+		{name: "self loop", start: "z", length: 3, want: []string{"z", "z", "z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := w.Path(tt.start, tt.length); !slices.Equal(got, tt.want) {
+				t.Errorf("Path(%s, %d) = %v, want %v", tt.start, tt.length, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalker_WalkWeights(t *testing.T) {
+	t.Parallel()
+
+	g := testGraph{
+		"hub":   {{Data: "left", Weight: 3}, {Data: "right", Weight: 1}, {Data: "left", Weight: 0}},
+		"left":  edges("hub"),
+		"right": edges("hub"),
+	}
+	w := mustWalker(t, g)
+
+	counts := make(map[string]int)
+	for node := range w.Walk("hub") {
+		counts[node]++
+		if counts["hub"] == 40_000 {
+			break
+		}
+	}
+
+	if got := float64(counts["left"]) / float64(counts["left"]+counts["right"]); got < 0.73 || got > 0.77 {
+		t.Errorf("walk took left %.3f of the time, want 0.75", got)
+	}
+}
+
+func TestWalker_WalkRestart(t *testing.T) {
+	t.Parallel()
+
+	// Without restarts a walk from a never returns to it
+	g := testGraph{"a": edges("b"), "b": edges("c"), "c": edges("c")}
+	w := mustWalker(t, g, WithRestart(0.5))
+
+	path := w.Path("a", 10_000)
+	if len(path) != 10_000 {
+		t.Fatalf("Path() returned %d nodes, want 10000", len(path))
+	}
+	restarts := 0
+	for _, node := range path[1:] {
+		if node == "a" {
+			restarts++
+		}
+	}
+	if got := float64(restarts) / float64(len(path)-1); got < 0.47 || got > 0.53 {
+		t.Errorf("walk restarted on %.3f of steps, want 0.5", got)
+	}
+}
+
+func TestWalker_WalkBias(t *testing.T) {
+	t.Parallel()
+
+	// Coming from a to b, a is a return, c is a neighbour of a and d isn't
+	g := testGraph{
+		"a": edges("b", "c"),
+		"b": edges("a", "c", "d"),
+		"c": edges("a", "b"),
+		"d": edges("b"),
+	}
+	tests := []struct {
+		name string
+		p, q float64
+	}{
+		{name: "rejection", p: 0.5, q: 2},
+		{name: "exact", p: 0.01, q: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := mustWalker(t, g, WithBias(tt.p, tt.q))
+
+			counts := make(map[string]int)
+			const n = 60_000
+			for range n {
+				path := w.Path("a", 3)
+				if path[1] != "b" {
+					continue
+				}
+				counts[path[2]]++
+			}
+
+			// Edge weights of 1 scaled by 1/p, 1 and 1/q
+			sum := 1/tt.p + 1 + 1/tt.q
+			total := float64(counts["a"] + counts["c"] + counts["d"])
+			want := map[string]float64{"a": 1 / tt.p / sum, "c": 1 / sum, "d": 1 / tt.q / sum}
+			for node, p := range want {
+				if got := float64(counts[node]) / total; got < p-0.02 || got > p+0.02 {
+					t.Errorf("biased step to %s had frequency %.3f, want %.3f", node, got, p)
+				}
+			}
+		})
+	}
+}
+
+func TestWalker_WalkExtremeBias(t *testing.T) {
+	t.Parallel()
+
+	// Returning is impossible on a directed cycle, so a tiny p costs nothing
+	cycle := testGraph{"a": edges("b"), "b": edges("c"), "c": edges("a")}
+	// Every step from the hub leaves the neighbourhood of the leaf it came from
+	star := testGraph{"hub": edges("x", "y", "z"), "x": edges("hub"), "y": edges("hub"), "z": edges("hub")}
+	tests := []struct {
+		name  string
+		g     testGraph
+		start string
+		p, q  float64
+	}{
+		{name: "tiny p without returns", g: cycle, start: "a", p: 1e-7, q: 1},
+		{name: "tiny p with returns", g: star, start: "hub", p: 1e-7, q: 1},
+		{name: "huge q", g: star, start: "hub", p: 1, q: 1e7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := mustWalker(t, tt.g, WithBias(tt.p, tt.q))
+			start := time.Now()
+			if path := w.Path(tt.start, 10_000); len(path) != 10_000 {
+				t.Errorf("Path() returned %d nodes, want 10000", len(path))
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("10000 steps took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestWithSource(t *testing.T) {
+	t.Parallel()
+
+	g := testGraph{"a": edges("b", "c"), "b": edges("a", "c"), "c": edges("a", "b")}
+	path := func() []string {
+		w := mustWalker(t, g, WithSource(rand.NewPCG(5, 6)), WithBias(0.25, 4), WithRestart(0.1))
+		return w.Path("a", 100)
+	}
+	if a, b := path(), path(); !slices.Equal(a, b) {
+		t.Errorf("walks with the same seed differ: %v and %v", a, b)
+	}
+}
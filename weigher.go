@@ -0,0 +1,96 @@
+package weightedoption
+
+import (
+	"math"
+	"math/big"
+)
+
+// Weigher is implemented by weight types which can express themselves exactly
+// as the fraction Num/Denom, such as a fixed-point Percent type. *big.Rat
+// satisfies Weigher.
+type Weigher interface {
+	Num() *big.Int
+	Denom() *big.Int
+}
+
+// WeigherOption is a struct that holds a data value and its associated
+// Weigher weight. The Weight is used to determine the probability of the data
+// being selected.
+type WeigherOption[DataType any, W Weigher] struct {
+	Weight W
+	Data   DataType
+}
+
+// NewWeigherOption creates a new WeigherOption with the provided data and
+// weight.
+func NewWeigherOption[DataType any, W Weigher](
+	data DataType,
+	weight W,
+) WeigherOption[DataType, W] {
+	return WeigherOption[DataType, W]{Data: data, Weight: weight}
+}
+
+// NewWeigherSelector creates a new Selector for selecting provided
+// WeigherOptions. The weights are converted to integers exactly by scaling
+// them with the least common multiple of their denominators and dividing out
+// their greatest common divisor. If the weight is less than or equal to 0, or
+// has a zero denominator, the option will be ignored. If all options are
+// ignored an error will be returned, as it will be if a scaled weight exceeds
+// the max integer value for this system's architecture.
+func NewWeigherSelector[DataType any, W Weigher](
+	opts ...WeigherOption[DataType, W],
+) (*Selector[DataType, uint], error) {
+	var (
+		options []DataType
+		indices []int
+		rats    []*big.Rat
+	)
+	lcm := big.NewInt(1)
+
+	// Filter out options with non-positive weights and find the common denominator
+	for i, opt := range opts {
+		num, denom := opt.Weight.Num(), opt.Weight.Denom()
+		if denom.Sign() == 0 {
+			continue
+		}
+
+		r := new(big.Rat).SetFrac(num, denom)
+		if r.Sign() <= 0 {
+			continue
+		}
+
+		options = append(options, opt.Data)
+		indices = append(indices, i)
+		rats = append(rats, r)
+		lcm = lcmInt(lcm, r.Denom())
+	}
+
+	if len(options) == 0 {
+		return nil, ErrNoValidOptions
+	}
+
+	scaled := make([]*big.Int, len(rats))
+	gcd := new(big.Int)
+	for i, r := range rats {
+		scaled[i] = new(big.Int).Mul(r.Num(), new(big.Int).Quo(lcm, r.Denom()))
+		gcd.GCD(nil, nil, gcd, scaled[i])
+	}
+
+	maxInt := big.NewInt(math.MaxInt)
+	weights := make([]uint, len(scaled))
+	for i, w := range scaled {
+		w.Quo(w, gcd)
+		if w.Cmp(maxInt) > 0 {
+			return nil, ErrSingleWeightOverflow
+		}
+		weights[i] = uint(w.Uint64())
+	}
+
+	return newSelector[DataType, uint](options, indices, weights)
+}
+
+// lcmInt returns the least common multiple of two positive integers.
+func lcmInt(a, b *big.Int) *big.Int {
+	gcd := new(big.Int).GCD(nil, nil, a, b)
+	return new(big.Int).Mul(new(big.Int).Quo(a, gcd), b)
+}
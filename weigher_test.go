@@ -0,0 +1,94 @@
+package weightedoption
+
+import (
+	"math"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// testPercent is a fixed-point percentage with two decimal places, e.g. 1250 is 12.50%.
+type testPercent int64
+
+func (p testPercent) Num() *big.Int   { return big.NewInt(int64(p)) }
+func (p testPercent) Denom() *big.Int { return big.NewInt(10_000) }
+
+func TestNewWeigherSelector(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cs          []WeigherOption[rune, *big.Rat]
+		wantWeights []uint
+		wantErr     error
+	}{
+		{
+			name:    "no options",
+			cs:      []WeigherOption[rune, *big.Rat]{},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name: "no options with weight greater than 0",
+			cs: []WeigherOption[rune, *big.Rat]{
+				NewWeigherOption('a', big.NewRat(0, 1)),
+				NewWeigherOption('b', big.NewRat(-1, 3)),
+			},
+			wantErr: ErrNoValidOptions,
+		},
+		{
+			name: "thirds and sixths",
+			cs: []WeigherOption[rune, *big.Rat]{
+				NewWeigherOption('a', big.NewRat(1, 3)),
+				NewWeigherOption('b', big.NewRat(1, 6)),
+				NewWeigherOption('c', big.NewRat(1, 2)),
+			},
+			wantWeights: []uint{2, 1, 3},
+		},
+		{
+			name: "weight overflow",
+			cs: []WeigherOption[rune, *big.Rat]{
+				NewWeigherOption('a', new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 64))),
+				NewWeigherOption('b', big.NewRat(1, 1)),
+			},
+			wantErr: ErrSingleWeightOverflow,
+		},
+		{
+			name: "total weight overflow",
+			cs: []WeigherOption[rune, *big.Rat]{
+				NewWeigherOption('a', big.NewRat(math.MaxInt, 1)),
+				NewWeigherOption('b', big.NewRat(1, 1)),
+			},
+			wantErr: ErrTotalWeightOverflow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := NewWeigherSelector(tt.cs...)
+			if err != tt.wantErr {
+				t.Fatalf("NewWeigherSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := weightsOf(s); !slices.Equal(got, tt.wantWeights) {
+				t.Errorf("NewWeigherSelector() weights = %v, want %v", got, tt.wantWeights)
+			}
+		})
+	}
+}
+
+func TestNewWeigherSelectorCustomType(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewWeigherSelector(
+		NewWeigherOption('a', testPercent(1250)),
+		NewWeigherOption('b', testPercent(8750)),
+	)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+	if got, want := weightsOf(s), []uint{1, 7}; !slices.Equal(got, want) {
+		t.Errorf("NewWeigherSelector() weights = %v, want %v", got, want)
+	}
+}
@@ -1,12 +1,15 @@
 package weightedoption
 
 import (
-	"cmp"
 	"errors"
 	"fmt"
+	"iter"
 	"math"
 	"math/rand/v2"
+	"reflect"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -21,7 +24,7 @@ var (
 
 // WeightConstraint is a type constraint for the Weight field of the Option struct.
 type WeightConstraint interface {
-	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float64
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float32 | ~float64
 }
 
 // Option is a struct that holds a data value and its associated weight.
@@ -41,19 +44,28 @@ func NewOption[DataType any, WeightType WeightConstraint](
 }
 
 // Selector is a struct that holds a slice of Options, their running total weights, and the total weight.
+// Options keep the order they were provided in, less any which can never be selected. The position of
+// an Option in the Selector is mapped back to its index in the provided Options by InputIndex.
 type Selector[DataType any, WeightType WeightConstraint] struct {
 	totalWeight          uint
 	cumulativeWeightSums []uint
 	options              []DataType
+	indices              []int
+	rng                  *rand.Rand
+	alias                *aliasTable
 }
 
-func isFloat64[WeightType WeightConstraint](val WeightType) bool {
-	_, is := any(val).(float64)
-	return is
+// isFloat reports whether WeightType is a floating point type, including
+// named types such as `type Rate float64`.
+func isFloat[WeightType WeightConstraint]() bool {
+	// Integer division truncates 1/2 to 0, floating point division doesn't.
+	return WeightType(1)/2 != 0
 }
 
-// countFractionalDigits counts the number of digits after the decimal point, returning an error for invalid floats.
-func countFractionalDigits(f float64) (int, error) {
+// countFractionalDigits counts the number of digits after the decimal point in
+// the shortest decimal representation of f for the given bit size, returning an
+// error for invalid floats.
+func countFractionalDigits(f float64, bitSize int) (int, error) {
 	// Handle invalid cases early
 	if math.IsNaN(f) || math.IsInf(f, 0) {
 		return 0, fmt.Errorf("invalid float: %v", f)
@@ -64,17 +76,17 @@ func countFractionalDigits(f float64) (int, error) {
 		return 0, nil
 	}
 
-	// Count the number of fractional digits by multiplying until the fractional part becomes zero
-	count := 0
-	for math.Trunc(f) != f {
-		f *= 10
-		count++
+	// Format with the fewest digits that round trip for the bit size, so a
+	// float32 0.1 counts as 1 digit rather than the digits of its float64 value
+	s := strconv.FormatFloat(f, 'f', -1, bitSize)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1, nil
 	}
-	return count, nil
+	return 0, nil
 }
 
-func processWeight(weight float64) (int, error) {
-	digits, err := countFractionalDigits(weight)
+func processWeight(weight float64, bitSize int) (int, error) {
+	digits, err := countFractionalDigits(weight, bitSize)
 	if err != nil {
 		return 0, fmt.Errorf("invalid weight: weight=%v", weight)
 	}
@@ -84,18 +96,12 @@ func processWeight(weight float64) (int, error) {
 // maxFractionalDigits finds the maximum number of fractional digits in a list of floats and returns an error if any float is invalid.
 func maxFractionalDigits[DataType any, WeightType WeightConstraint](options []Option[DataType, WeightType]) (int, error) {
 	maxDigits := 0
+	bitSize := reflect.TypeFor[WeightType]().Bits()
 
 	for _, opt := range options {
-		var digits int
-
-		switch weight := any(opt.Weight).(type) {
-		case float64:
-			_, err := processWeight(weight)
-			if err != nil {
-				return 0, fmt.Errorf("invalid float64 weight for option: option=%v, error=%v", opt.Data, err)
-			}
-		default:
-			return 0, fmt.Errorf("weight failed type assertion, not a valid float64: option=%v, weight=%v", opt.Data, opt.Weight)
+		digits, err := processWeight(float64(opt.Weight), bitSize)
+		if err != nil {
+			return 0, fmt.Errorf("invalid float weight for option: option=%v, error=%v", opt.Data, err)
 		}
 
 		if digits > maxDigits {
@@ -108,75 +114,162 @@ func maxFractionalDigits[DataType any, WeightType WeightConstraint](options []Op
 
 const decimalBase = 10
 
-// scaleFloat64ToInt scales float64 weights to integers based on the maximum number of fractional digits.
-func scaleFloat64ToInt[DataType any, WeightType WeightConstraint](maxPrecision int, options []Option[DataType, WeightType]) ([]Option[DataType, WeightType], error) {
+// scaleFloatToInt scales float weights to integers based on the maximum number of fractional digits.
+func scaleFloatToInt[DataType any, WeightType WeightConstraint](maxPrecision int, options []Option[DataType, WeightType]) ([]uint, error) {
 	scaleFactor := math.Pow(decimalBase, float64(maxPrecision))
+	weights := make([]uint, len(options))
 	for i, opt := range options {
-		switch weight := any(opt.Weight).(type) {
-		case float64:
-			scaledWeight := int(math.Round(weight * scaleFactor))
-			options[i].Weight = WeightType(scaledWeight)
-		default:
-			return nil, fmt.Errorf("weight failed type assertion, not a valid float64: option=%v, weight=%v", opt.Data, opt.Weight)
+		scaledWeight := math.Round(float64(opt.Weight) * scaleFactor)
+		// float64(math.MaxInt) rounds up to 2^63, so anything at or above it overflows
+		if scaledWeight >= math.MaxInt {
+			return nil, ErrSingleWeightOverflow
 		}
+		weights[i] = uint(scaledWeight)
 	}
-	return options, nil
+	return weights, nil
 }
 
+// prepareOptions filters out Options which can never be selected and returns
+// the remaining Options, in their original order, along with their indexes in
+// options and their weights as integers. options is never modified. Float weights are
+// scaled by 10^precision, or by enough to keep every fractional digit if
+// precision is negative.
 func prepareOptions[DataType any, WeightType WeightConstraint](
+	precision int,
 	options ...Option[DataType, WeightType],
-) ([]Option[DataType, WeightType], error) {
-	var filteredOptions []Option[DataType, WeightType]
+) ([]Option[DataType, WeightType], []int, []uint, error) {
+	var (
+		filteredOptions []Option[DataType, WeightType]
+		indices         []int
+	)
 
 	// Filter out options with non-positive weights
-	for _, opt := range options {
+	for i, opt := range options {
 		if opt.Weight > 0 {
 			filteredOptions = append(filteredOptions, opt)
+			indices = append(indices, i)
 		}
 	}
 
 	// Return an error if no valid options are found
 	if len(filteredOptions) == 0 {
-		return nil, ErrNoValidOptions
+		return nil, nil, nil, ErrNoValidOptions
 	}
 
-	// Sort options by weight in ascending order
-	slices.SortFunc(options, func(a, b Option[DataType, WeightType]) int {
-		return cmp.Compare(a.Weight, b.Weight)
-	})
-
-	// If integers just return the list
-	if !isFloat64(filteredOptions[0].Weight) {
-		return filteredOptions, nil
+	// If integers just convert the weights
+	if !isFloat[WeightType]() {
+		weights := make([]uint, len(filteredOptions))
+		for i, opt := range filteredOptions {
+			// Compare before converting so 64-bit weights can't truncate on 32-bit systems
+			if uint64(opt.Weight) > math.MaxInt {
+				return nil, nil, nil, ErrSingleWeightOverflow
+			}
+			weights[i] = uint(opt.Weight)
+		}
+		return filteredOptions, indices, weights, nil
 	}
 
 	// Find the maximum number of fractional digits, returning an error if any float is invalid.
 	maxDigits, err := maxFractionalDigits(filteredOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	if precision >= 0 {
+		maxDigits = precision
 	}
 
-	return scaleFloat64ToInt(maxDigits, filteredOptions)
+	weights, err := scaleFloatToInt(maxDigits, filteredOptions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// With a fixed precision small weights can round down to 0
+	n := 0
+	for i, weight := range weights {
+		if weight > 0 {
+			filteredOptions[n] = filteredOptions[i]
+			indices[n] = indices[i]
+			weights[n] = weight
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, nil, nil, ErrNoValidOptions
+	}
+	return filteredOptions[:n], indices[:n], weights[:n], nil
 }
 
 // NewSelector creates a new Selector for selecting provided Options. The Weights
-// provided must be a positive integer or float64. If the weight is a float64,
+// provided must be a positive integer or float. If the weight is a float,
 // it will be scaled to an integer. If the weight is less than or equal to 0,
 // the option will be ignored. If all options have a weight of 0 or lower,
 // an error will be returned. If math.Inf(1) is used an error will be returned.
+// NewSelector is NewSelectorWith without any SelectorOptions.
 func NewSelector[DataType any, WeightType WeightConstraint](
 	opts ...Option[DataType, WeightType],
 ) (*Selector[DataType, WeightType], error) {
-	opts, err := prepareOptions(opts...)
+	return NewSelectorWith(opts)
+}
+
+// NewSelectorWith creates a new Selector for selecting provided Options, like
+// NewSelector, configured by the provided SelectorOptions.
+func NewSelectorWith[DataType any, WeightType WeightConstraint](
+	opts []Option[DataType, WeightType],
+	cfg ...SelectorOption,
+) (*Selector[DataType, WeightType], error) {
+	c := newSelectorConfig(cfg)
+
+	if c.strict {
+		if err := validate(c, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	opts, indices, weights, err := prepareOptions(c.precision, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var totalWeight uint
-	cumulativeWeightSums := make([]uint, len(opts))
 	options := make([]DataType, len(opts))
 	for i, opt := range opts {
-		weight := uint(opt.Weight)
+		options[i] = opt.Data
+	}
+
+	if c.mergeDuplicates {
+		options, indices, weights, err = mergeDuplicates(options, indices, weights)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := newSelector[DataType, WeightType](options, indices, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.algorithm == AlgorithmAlias {
+		if err := s.buildAliasTable(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.source != nil {
+		s.rng = rand.New(c.source)
+	}
+
+	return s, nil
+}
+
+// newSelector creates a new Selector from Options already split into their
+// data, input indexes and integer weights, checking the weights for overflow.
+func newSelector[DataType any, WeightType WeightConstraint](
+	options []DataType,
+	indices []int,
+	weights []uint,
+) (*Selector[DataType, WeightType], error) {
+	var totalWeight uint
+	cumulativeWeightSums := make([]uint, len(weights))
+	for i, weight := range weights {
 		// Check for overflow
 		if weight > math.MaxInt {
 			return nil, ErrSingleWeightOverflow
@@ -187,7 +280,6 @@ func NewSelector[DataType any, WeightType WeightConstraint](
 		}
 
 		totalWeight += weight
-		options[i] = opt.Data
 		cumulativeWeightSums[i] = totalWeight
 	}
 
@@ -197,14 +289,121 @@ func NewSelector[DataType any, WeightType WeightConstraint](
 
 	return &Selector[DataType, WeightType]{
 		options:              options,
+		indices:              indices,
 		cumulativeWeightSums: cumulativeWeightSums,
 		totalWeight:          totalWeight,
 	}, nil
 }
 
+// uintN returns a random number in [0, n) from the Selector's random number
+// generator, or from the global one if none was set.
+func (s Selector[DataType, WeightType]) uintN(n uint) uint {
+	if s.rng != nil {
+		return s.rng.UintN(n)
+	}
+	return rand.UintN(n)
+}
+
+// float64 returns a random number in [0.0, 1.0) from the Selector's random
+// number generator, or from the global one if none was set.
+func (s Selector[DataType, WeightType]) float64() float64 {
+	if s.rng != nil {
+		return s.rng.Float64()
+	}
+	return rand.Float64()
+}
+
+// selectPosition returns the position of an Option selected from Selector.Options
+func (s Selector[DataType, WeightType]) selectPosition() int {
+	if s.alias != nil {
+		return s.selectAlias()
+	}
+
+	r := s.uintN(s.totalWeight) + 1
+	i, _ := slices.BinarySearch(s.cumulativeWeightSums, r)
+	return i
+}
+
 // Select returns a single DataType from Selector.Options
 func (s Selector[DataType, WeightType]) Select() DataType {
-	r := rand.UintN(s.totalWeight) + 1
-	i, _ := slices.BinarySearch(s.cumulativeWeightSums, r)
-	return s.options[i]
+	return s.options[s.selectPosition()]
+}
+
+// SelectIndex selects an Option like Select, but returns its index in the
+// Options the Selector was created from instead of its DataType.
+func (s Selector[DataType, WeightType]) SelectIndex() int {
+	return s.indices[s.selectPosition()]
+}
+
+// Len returns the number of Options in the Selector.
+func (s Selector[DataType, WeightType]) Len() int {
+	return len(s.options)
+}
+
+// weight returns the integer weight of the Option at position i.
+func (s Selector[DataType, WeightType]) weight(i int) uint {
+	if i == 0 {
+		return s.cumulativeWeightSums[0]
+	}
+	return s.cumulativeWeightSums[i] - s.cumulativeWeightSums[i-1]
+}
+
+// InputIndex returns the index, in the Options the Selector was created from,
+// of the Option at position i in the Selector. When duplicates are merged it is
+// the index of the first of them.
+func (s Selector[DataType, WeightType]) InputIndex(i int) int {
+	return s.indices[i]
+}
+
+// InputIndices returns a copy of the mapping from every position in the
+// Selector to its index in the Options the Selector was created from.
+func (s Selector[DataType, WeightType]) InputIndices() []int {
+	return slices.Clone(s.indices)
+}
+
+// SelectN appends n DataType selected from Selector.Options to dst and returns
+// the extended slice. If n is less than 1 dst is returned unchanged.
+func (s Selector[DataType, WeightType]) SelectN(n int, dst []DataType) []DataType {
+	if n < 1 {
+		return dst
+	}
+	dst = slices.Grow(dst, n)
+	for i := 0; i < n; i++ {
+		dst = append(dst, s.Select())
+	}
+	return dst
+}
+
+// Stream returns an endless sequence of DataType drawn from Selector.Options.
+// The sequence stops only when the caller stops ranging over it.
+func (s Selector[DataType, WeightType]) Stream() iter.Seq[DataType] {
+	return stream(s.Select)
+}
+
+// Take returns a sequence of n DataType drawn from Selector.Options. If n is
+// less than 1 the sequence is empty.
+func (s Selector[DataType, WeightType]) Take(n int) iter.Seq[DataType] {
+	return take(s.Select, n)
+}
+
+// stream returns an endless sequence of values returned by next.
+func stream[DataType any](next func() DataType) iter.Seq[DataType] {
+	return func(yield func(DataType) bool) {
+		for {
+			if !yield(next()) {
+				return
+			}
+		}
+	}
+}
+
+// take returns a sequence of n values returned by next.
+func take[DataType any](next func() DataType, n int) iter.Seq[DataType] {
+	return func(yield func(DataType) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(next()) {
+				return
+			}
+		}
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -62,6 +63,114 @@ func TestNewSelector(t *testing.T) {
 	}
 }
 
+func TestNewSelectorPreservesInput(t *testing.T) {
+	t.Parallel()
+
+	cs := []Option[rune, int]{
+		{Data: 'a', Weight: 5},
+		{Data: 'b', Weight: 0},
+		{Data: 'c', Weight: 1},
+		{Data: 'd', Weight: -3},
+		{Data: 'e', Weight: 3},
+	}
+	original := slices.Clone(cs)
+
+	s, err := NewSelector(cs...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	if !slices.Equal(cs, original) {
+		t.Errorf("NewSelector() modified its input: got %v, want %v", cs, original)
+	}
+	if want := []rune{'a', 'c', 'e'}; !slices.Equal(s.options, want) {
+		t.Errorf("NewSelector() options = %c, want %c", s.options, want)
+	}
+	if got := s.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	want := []int{0, 2, 4}
+	if got := s.InputIndices(); !slices.Equal(got, want) {
+		t.Errorf("InputIndices() = %v, want %v", got, want)
+	}
+	for i, index := range want {
+		if got := s.InputIndex(i); got != index {
+			t.Errorf("InputIndex(%d) = %d, want %d", i, got, index)
+		}
+	}
+}
+
+func TestSelector_SelectIndex(t *testing.T) {
+	t.Parallel()
+
+	cs := []Option[rune, int]{
+		{Data: 'a', Weight: 0},
+		{Data: 'b', Weight: 1},
+		{Data: 'c', Weight: 0},
+		{Data: 'd', Weight: 2},
+		{Data: 'b', Weight: 3},
+	}
+	for _, cfg := range [][]SelectorOption{nil, {WithMergeDuplicates()}, {WithAlgorithm(AlgorithmAlias)}} {
+		s, err := NewSelectorWith(cs, cfg...)
+		if err != nil {
+			t.Fatal("Failed to create Selector:", err)
+		}
+
+		for i := 0; i < 1000; i++ {
+			index := s.SelectIndex()
+			if cs[index].Weight <= 0 {
+				t.Fatalf("SelectIndex() = %d, which has weight %d", index, cs[index].Weight)
+			}
+		}
+	}
+}
+
+type testRate float64
+
+func TestNewSelectorFloatWeights(t *testing.T) {
+	t.Parallel()
+
+	t.Run("float64", func(t *testing.T) {
+		t.Parallel()
+		testFloatWeights(t, []Option[rune, float64]{{Data: 'a', Weight: 0.6}, {Data: 'b', Weight: 18.86}}, []uint{60, 1886})
+	})
+	t.Run("float32", func(t *testing.T) {
+		t.Parallel()
+		testFloatWeights(t, []Option[rune, float32]{{Data: 'a', Weight: 0.1}, {Data: 'b', Weight: 2.5}}, []uint{1, 25})
+	})
+	t.Run("named float type", func(t *testing.T) {
+		t.Parallel()
+		testFloatWeights(t, []Option[rune, testRate]{{Data: 'a', Weight: 1.5}, {Data: 'b', Weight: 3}}, []uint{15, 30})
+	})
+	t.Run("infinite weight", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewSelector(NewOption('a', math.Inf(1)))
+		if err == nil {
+			t.Error("NewSelector() with an infinite weight returned no error")
+		}
+	})
+	t.Run("scaled weight overflow", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewSelector(NewOption('a', 1e300), NewOption('b', 0.5))
+		if err != ErrSingleWeightOverflow {
+			t.Errorf("NewSelector() error = %v, wantErr %v", err, ErrSingleWeightOverflow)
+		}
+	})
+}
+
+func testFloatWeights[WeightType WeightConstraint](t *testing.T, cs []Option[rune, WeightType], want []uint) {
+	t.Helper()
+
+	s, err := NewSelector(cs...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	if got := weightsOf(s); !slices.Equal(got, want) {
+		t.Errorf("NewSelector() scaled weights = %v, want %v", got, want)
+	}
+}
+
 func TestSelector_Select(t *testing.T) {
 	t.Parallel()
 
@@ -80,6 +189,96 @@ func TestSelector_Select(t *testing.T) {
 	verifyFrequencyCounts(t, counts, options)
 }
 
+func TestSelector_Stream(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	picker, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	counts := make(map[int]int)
+	n := 0
+	for c := range picker.Stream() {
+		counts[c]++
+		n++
+		if n == testIterations {
+			break
+		}
+	}
+
+	verifyFrequencyCounts(t, counts, options)
+}
+
+func TestSelector_Take(t *testing.T) {
+	t.Parallel()
+
+	picker, err := NewSelector(NewOption('a', 1), NewOption('b', 2))
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "negative", n: -1, want: 0},
+		{name: "zero", n: 0, want: 0},
+		{name: "one", n: 1, want: 1},
+		{name: "many", n: 100, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := slices.Collect(picker.Take(tt.n))
+			if len(got) != tt.want {
+				t.Errorf("Take(%d) returned %d values, want %d", tt.n, len(got), tt.want)
+			}
+		})
+	}
+
+	t.Run("early stop", func(t *testing.T) {
+		t.Parallel()
+		n := 0
+		for range picker.Take(10) {
+			n++
+			if n == 3 {
+				break
+			}
+		}
+		if n != 3 {
+			t.Errorf("Take(10) yielded %d values after break, want 3", n)
+		}
+	})
+}
+
+func TestSelector_SelectN(t *testing.T) {
+	t.Parallel()
+
+	options := mockFrequencyOptions(t, testOptions)
+	picker, err := NewSelector(options...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+
+	if got := picker.SelectN(0, nil); got != nil {
+		t.Errorf("SelectN(0, nil) = %v, want nil", got)
+	}
+
+	got := picker.SelectN(testIterations, []int{-1})
+	if len(got) != testIterations+1 || got[0] != -1 {
+		t.Fatalf("SelectN() did not append to dst: len = %d, first = %d", len(got), got[0])
+	}
+
+	counts := make(map[int]int)
+	for _, c := range got[1:] {
+		counts[c]++
+	}
+	verifyFrequencyCounts(t, counts, options)
+}
+
 func mockFrequencyOptions(t *testing.T, n int) []Option[int, int] {
 	t.Helper()
 	options := make([]Option[int, int], 0, n)
@@ -103,6 +302,28 @@ func verifyFrequencyCounts(t *testing.T, counts map[int]int, options []Option[in
 	}
 }
 
+func mustSelector[DataType any, WeightType WeightConstraint](
+	t *testing.T,
+	opts ...Option[DataType, WeightType],
+) *Selector[DataType, WeightType] {
+	t.Helper()
+	s, err := NewSelector(opts...)
+	if err != nil {
+		t.Fatal("Failed to create Selector:", err)
+	}
+	return s
+}
+
+func weightsOf[DataType any, WeightType WeightConstraint](s *Selector[DataType, WeightType]) []uint {
+	var total uint
+	weights := make([]uint, len(s.cumulativeWeightSums))
+	for i, sum := range s.cumulativeWeightSums {
+		weights[i] = sum - total
+		total = sum
+	}
+	return weights
+}
+
 const (
 	BMMinOptions int = 10
 	BMMaxOptions int = 10_000_000